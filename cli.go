@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/db"
+	"github.com/DurgeshKr2242/blogassessment/domains"
+)
+
+// loadConfig loads the configuration for CLI subcommands.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// openBlogPostDomain connects the blog post domain configured by cfg for CLI
+// subcommands. The returned func closes the connection.
+func openBlogPostDomain(cfg *config.Config) (domains.BlogPostDomain, func(), error) {
+	if cfg.Storage == config.StorageMemory {
+		return domains.NewMemoryBlogPostDomain(), func() {}, nil
+	}
+
+	database, err := db.ConnectDB(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to database: %w", err)
+	}
+	if cfg.Storage == config.StorageSQLite {
+		return domains.NewSQLiteBlogPostDomain(database), func() { database.Close() }, nil
+	}
+	return domains.NewBlogPostDomain(database), func() { database.Close() }, nil
+}
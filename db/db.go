@@ -1,28 +1,110 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/DurgeshKr2242/blogassessment/config"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-// ConnectDB opens a connection to PostgreSQL using the standard library
+// ConnectDB opens a connection pool to the database selected by cfg.Storage,
+// PostgreSQL unless SQLite is configured, sized by cfg.Pool. It retries
+// reaching the database as cfg.ConnectRetry allows, so the server can start
+// before the database does.
 func ConnectDB(cfg *config.Config) (*sql.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
-	)
+	driver, dsn := "postgres", cfg.DatabaseURL
+	if dsn == "" {
+		dsn = fmt.Sprintf(
+			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
+		)
+	}
+	if cfg.Storage == config.StorageSQLite {
+		driver, dsn = "sqlite", sqliteDSN(cfg.SQLitePath)
+	}
 
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("could not open db: %w", err)
 	}
-	
-	if err := db.Ping(); err != nil {
+	configurePool(db, cfg.Pool)
+
+	if err := ping(context.Background(), db, cfg.ConnectRetry); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("could not ping db: %w", err)
 	}
 
 	return db, nil
 }
+
+// sqliteDSN adds the connection settings to the SQLite database at path,
+// which may carry query parameters of its own, such as file:blog.db?mode=rwc.
+// Writers wait for each other instead of failing with SQLITE_BUSY, and
+// transactions take the write lock up front.
+func sqliteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator +
+		"_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate"
+}
+
+// OpenReplica opens a connection pool to the Postgres read replica at dsn,
+// sized by pool. It doesn't wait for the replica to answer: reads go to the
+// primary until the replica passes a health check.
+func OpenReplica(dsn string, pool config.PoolSettings) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open replica: %w", err)
+	}
+	configurePool(db, pool)
+	return db, nil
+}
+
+func configurePool(db *sql.DB, pool config.PoolSettings) {
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+}
+
+// ping pings db until it answers, waiting between attempts with exponential
+// backoff, and returns the last error once retry.MaxWait has passed.
+func ping(ctx context.Context, db *sql.DB, retry config.ConnectRetry) error {
+	deadline := time.Now().Add(retry.MaxWait)
+	backoff := retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := pingBefore(ctx, db, deadline, retry.MaxWait > 0)
+		if err == nil {
+			return nil
+		}
+
+		// The next attempt has to start before the deadline to have a chance.
+		if time.Until(deadline) <= backoff {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+		slog.WarnContext(ctx, "Database is not reachable yet, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, retry.MaxBackoff)
+	}
+}
+
+// pingBefore pings db, giving up at deadline when bounded is true, so an
+// unreachable host can't hold up startup past the maximum wait.
+func pingBefore(ctx context.Context, db *sql.DB, deadline time.Time, bounded bool) error {
+	if bounded {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	return db.PingContext(ctx)
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+)
+
+// flakyConnector refuses the first failures connections.
+type flakyConnector struct {
+	failures atomic.Int32
+	attempts atomic.Int32
+}
+
+var errRefused = errors.New("connection refused")
+
+func (c *flakyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.attempts.Add(1)
+	if c.failures.Add(-1) >= 0 {
+		return nil, errRefused
+	}
+	return stubConn{}, nil
+}
+
+func (c *flakyConnector) Driver() driver.Driver { return nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (stubConn) Close() error                              { return nil }
+func (stubConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func TestPing(t *testing.T) {
+	retry := config.ConnectRetry{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+		MaxWait:        200 * time.Millisecond,
+	}
+	cases := map[string]struct {
+		failures     int32
+		retry        config.ConnectRetry
+		wantAttempts int32
+		wantErr      bool
+	}{
+		"When the database is up":          {failures: 0, retry: retry, wantAttempts: 1},
+		"When the database comes up later": {failures: 4, retry: retry, wantAttempts: 5},
+		"When the database never comes up": {failures: 1 << 20, retry: retry, wantErr: true},
+		"When retrying is disabled": {
+			failures:     1,
+			retry:        config.ConnectRetry{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			connector := &flakyConnector{}
+			connector.failures.Store(tc.failures)
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			start := time.Now()
+			err := ping(context.Background(), db, tc.retry)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr && !errors.Is(err, errRefused) {
+				t.Errorf("error does not wrap the last failure: %v", err)
+			}
+			if tc.wantAttempts != 0 && connector.attempts.Load() != tc.wantAttempts {
+				t.Errorf("made %d attempts, want %d", connector.attempts.Load(), tc.wantAttempts)
+			}
+			if elapsed := time.Since(start); elapsed > tc.retry.MaxWait+100*time.Millisecond {
+				t.Errorf("gave up after %v, past the maximum wait of %v", elapsed, tc.retry.MaxWait)
+			}
+		})
+	}
+}
+
+func TestConnectDB_Pool(t *testing.T) {
+	db, err := ConnectDB(&config.Config{
+		Storage:    config.StorageSQLite,
+		SQLitePath: t.TempDir() + "/blog.db",
+		Pool:       config.PoolSettings{MaxOpenConns: 3, MaxIdleConns: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if got := db.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("pool allows %d connections, want 3", got)
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	const settings = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate"
+	cases := map[string]struct {
+		path string
+		want string
+	}{
+		"When the path is a file":          {path: "blog.db", want: "blog.db?" + settings},
+		"When the path has a query":        {path: "file:blog.db?mode=rwc", want: "file:blog.db?mode=rwc&" + settings},
+		"When the path is an in-memory db": {path: "file::memory:?cache=shared", want: "file::memory:?cache=shared&" + settings},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			if got := sqliteDSN(tc.path); got != tc.want {
+				t.Errorf("sqliteDSN(%q) = %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
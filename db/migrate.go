@@ -0,0 +1,324 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+)
+
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock key held while migrating, so
+// replicas starting at the same time run migrations one after the other.
+const migrationLockID = 7283001450221
+
+// NilVersion is the schema version of a database without any migration.
+const NilVersion = -1
+
+var (
+	ErrorDirtyDatabase    = errors.New("database is dirty, fix it and run migrate force")
+	ErrorNoMigration      = errors.New("no migration to roll back")
+	ErrorUnknownMigration = errors.New("unknown migration version")
+	ErrorSchemaNotCurrent = errors.New("schema is not at the latest migration")
+
+	migrationFileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+// Migration is a pair of embedded up and down SQL scripts.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes the schema version of a database.
+type MigrationStatus struct {
+	Version int
+	Dirty   bool
+	Latest  int
+	Pending []Migration
+}
+
+// Migrator applies the embedded migrations. The schema_migrations table
+// layout is the one used by golang-migrate, so databases migrated with the
+// migrate CLI keep working.
+type Migrator struct {
+	db         *sql.DB
+	storage    string
+	migrations []Migration
+}
+
+// NewMigrator returns a Migrator for the migrations embedded in the binary
+// for the given storage, config.StoragePostgres or config.StorageSQLite.
+func NewMigrator(db *sql.DB, storage string) (*Migrator, error) {
+	dir := "migrations"
+	if storage == config.StorageSQLite {
+		dir = "migrations/sqlite"
+	}
+	migrations, err := loadMigrations(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, storage: storage, migrations: migrations}, nil
+}
+
+// Migrations returns the embedded migrations in version order.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		status, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if status.Dirty {
+			return ErrorDirtyDatabase
+		}
+		for _, migration := range status.Pending {
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		status, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if status.Dirty {
+			return ErrorDirtyDatabase
+		}
+		if status.Version == NilVersion {
+			return ErrorNoMigration
+		}
+
+		index := m.index(status.Version)
+		if index < 0 {
+			return fmt.Errorf("%w: %d", ErrorUnknownMigration, status.Version)
+		}
+		previous := NilVersion
+		if index > 0 {
+			previous = m.migrations[index-1].Version
+		}
+
+		migration := m.migrations[index]
+		if err := m.apply(ctx, conn, migration.Down, previous); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	})
+}
+
+// Force sets the schema version and clears the dirty flag without running
+// any migration. Use NilVersion to mark the database as unmigrated.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version != NilVersion && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrorUnknownMigration, version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := setVersion(ctx, tx, version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// Status reports the current schema version and the pending migrations.
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	return m.status(ctx, conn)
+}
+
+// Check returns an error unless the schema is at the latest embedded
+// migration and not dirty. Unlike Status it never writes, so it suits
+// readiness checks.
+func (m *Migrator) Check(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	status, err := m.status(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w: version %d", ErrorDirtyDatabase, status.Version)
+	}
+	if status.Version != status.Latest {
+		return fmt.Errorf("%w: version %d, expected %d", ErrorSchemaNotCurrent, status.Version, status.Latest)
+	}
+	return nil
+}
+
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) (*MigrationStatus, error) {
+	status := &MigrationStatus{Version: NilVersion, Latest: NilVersion}
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		Scan(&status.Version, &status.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version > status.Version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	if len(m.migrations) > 0 {
+		status.Latest = m.migrations[len(m.migrations)-1].Version
+	}
+	return status, nil
+}
+
+// apply runs a migration script and records the new version in one transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, script string, version int) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if err := setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock. SQLite serializes writers itself and has no advisory locks.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.storage == config.StorageSQLite {
+		if err := ensureVersionTable(ctx, conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("could not acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) index(version int) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+       CREATE TABLE IF NOT EXISTS schema_migrations (
+           version BIGINT  NOT NULL PRIMARY KEY,
+           dirty   BOOLEAN NOT NULL
+       )
+    `)
+	if err != nil {
+		return fmt.Errorf("could not create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func setVersion(ctx context.Context, tx *sql.Tx, version int) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if version == NilVersion {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, FALSE)`, version)
+	return err
+}
+
+// loadMigrations pairs the up and down scripts found in dir.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
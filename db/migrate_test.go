@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/000002_add_column.up.sql":   {Data: []byte("ALTER TABLE t ADD COLUMN c INT;")},
+		"migrations/000002_add_column.down.sql": {Data: []byte("ALTER TABLE t DROP COLUMN c;")},
+		"migrations/000001_init.up.sql":         {Data: []byte("CREATE TABLE t (id INT);")},
+		"migrations/000001_init.down.sql":       {Data: []byte("DROP TABLE t;")},
+		"migrations/README.md":                  {Data: []byte("not a migration")},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("unexpected number of migrations: %d", len(migrations))
+	}
+	if m := migrations[0]; m.Version != 1 || m.Name != "init" || m.Up != "CREATE TABLE t (id INT);" || m.Down != "DROP TABLE t;" {
+		t.Errorf("unexpected first migration: %+v", m)
+	}
+	if m := migrations[1]; m.Version != 2 || m.Name != "add_column" {
+		t.Errorf("unexpected second migration: %+v", m)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	counts := map[string]int{}
+	for _, dir := range []string{"migrations", "migrations/sqlite"} {
+		migrations, err := loadMigrations(migrationFiles, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[dir] = len(migrations)
+
+		for i, m := range migrations {
+			if m.Version != i+1 {
+				t.Errorf("%s: migration %s has version %d, want %d", dir, m.Name, m.Version, i+1)
+			}
+			if m.Up == "" || m.Down == "" {
+				t.Errorf("%s: migration %d_%s is missing its up or down script", dir, m.Version, m.Name)
+			}
+		}
+	}
+	if counts["migrations"] != counts["migrations/sqlite"] {
+		t.Errorf("SQLite migrations do not mirror the Postgres ones: %v", counts)
+	}
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	cfg := &config.Config{Storage: config.StorageSQLite, SQLitePath: filepath.Join(t.TempDir(), "blog.db")}
+	database, err := ConnectDB(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	migrator, err := NewMigrator(database, cfg.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	latest := migrator.Migrations()[len(migrator.Migrations())-1].Version
+
+	assertVersion := func(want int) {
+		t.Helper()
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Version != want || status.Dirty {
+			t.Errorf("unexpected status: %+v, want version %d", status, want)
+		}
+	}
+
+	assertVersion(NilVersion)
+	if err := migrator.Check(ctx); !errors.Is(err, ErrorSchemaNotCurrent) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(latest)
+	if err := migrator.Check(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := migrator.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(latest - 1)
+	if err := migrator.Check(ctx); !errors.Is(err, ErrorSchemaNotCurrent) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(latest)
+
+	for range migrator.Migrations() {
+		if err := migrator.Down(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertVersion(NilVersion)
+	if err := migrator.Down(ctx); !errors.Is(err, ErrorNoMigration) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := migrator.Force(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(1)
+	if err := migrator.Force(ctx, 999); !errors.Is(err, ErrorUnknownMigration) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS blog_posts_slug_key;
+
+ALTER TABLE blog_posts DROP COLUMN IF EXISTS draft;
+ALTER TABLE blog_posts DROP COLUMN IF EXISTS tags;
+ALTER TABLE blog_posts DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,6 @@
+-- Slug identifies a post independently of its generated id, e.g. for imports.
+ALTER TABLE blog_posts ADD COLUMN IF NOT EXISTS slug  VARCHAR(255);
+ALTER TABLE blog_posts ADD COLUMN IF NOT EXISTS tags  TEXT[]  NOT NULL DEFAULT '{}';
+ALTER TABLE blog_posts ADD COLUMN IF NOT EXISTS draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS blog_posts_slug_key ON blog_posts (slug);
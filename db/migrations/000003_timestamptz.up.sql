@@ -0,0 +1,4 @@
+-- Existing timestamps were written in UTC.
+ALTER TABLE blog_posts
+    ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP WITH TIME ZONE USING updated_at AT TIME ZONE 'UTC';
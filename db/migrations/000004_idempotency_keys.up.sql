@@ -0,0 +1,13 @@
+-- Responses of POST requests sent with an Idempotency-Key header. status and
+-- response stay NULL while the first request is still being processed.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           VARCHAR(255)                NOT NULL PRIMARY KEY,
+    request_hash  CHAR(64)                    NOT NULL,
+    status        INTEGER,
+    content_type  VARCHAR(255),
+    response      BYTEA,
+    created_at    TIMESTAMP WITH TIME ZONE    NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMP WITH TIME ZONE    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
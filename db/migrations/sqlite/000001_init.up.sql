@@ -0,0 +1,10 @@
+-- Create blog_posts table. Ids are UUIDs generated by the application and
+-- timestamps are UTC RFC 3339 strings with a fixed width, so they sort as text.
+CREATE TABLE IF NOT EXISTS blog_posts (
+    id          TEXT            NOT NULL PRIMARY KEY,
+    title       VARCHAR(255)    NOT NULL,
+    description TEXT,
+    body        TEXT,
+    created_at  TEXT            NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000000Z', 'now')),
+    updated_at  TEXT            NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000000Z', 'now'))
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS blog_posts_slug_key;
+
+ALTER TABLE blog_posts DROP COLUMN draft;
+ALTER TABLE blog_posts DROP COLUMN tags;
+ALTER TABLE blog_posts DROP COLUMN slug;
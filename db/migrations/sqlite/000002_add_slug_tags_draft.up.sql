@@ -0,0 +1,6 @@
+-- Tags are stored as a JSON array.
+ALTER TABLE blog_posts ADD COLUMN slug  VARCHAR(255);
+ALTER TABLE blog_posts ADD COLUMN tags  TEXT    NOT NULL DEFAULT '[]';
+ALTER TABLE blog_posts ADD COLUMN draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS blog_posts_slug_key ON blog_posts (slug);
@@ -0,0 +1,3 @@
+-- SQLite timestamps are already stored as UTC RFC 3339 text. This migration
+-- only keeps the version numbers in step with the Postgres migrations.
+SELECT 1;
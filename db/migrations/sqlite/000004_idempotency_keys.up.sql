@@ -0,0 +1,14 @@
+-- Responses of POST requests sent with an Idempotency-Key header. status and
+-- response stay NULL while the first request is still being processed.
+-- Timestamps use the fixed-width UTC format of blog_posts.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           TEXT        NOT NULL PRIMARY KEY,
+    request_hash  TEXT        NOT NULL,
+    status        INTEGER,
+    content_type  TEXT,
+    response      BLOB,
+    created_at    TEXT        NOT NULL,
+    expires_at    TEXT        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -1,26 +1,45 @@
 package domains
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/DurgeshKr2242/blogassessment/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 // BlogPostDomain defines the operations for blog posts.
+// Every method stops its database work once ctx is done.
 type BlogPostDomain interface {
-	CreateBlogPost(blog *models.BlogPost) (*uuid.UUID, error)
-	GetBlogPost(ID *uuid.UUID) (*models.BlogPost, error)
-	GetBlogPosts() ([]models.BlogPost, error)
-	UpdateBlogPost(post *models.BlogPost) error
-	DeleteBlogPost(ID *uuid.UUID) error
+	CreateBlogPost(ctx context.Context, blog *models.BlogPost) (*uuid.UUID, error)
+	GetBlogPost(ctx context.Context, ID *uuid.UUID) (*models.BlogPost, error)
+	GetBlogPostBySlug(ctx context.Context, slug string) (*models.BlogPost, error)
+	GetBlogPosts(ctx context.Context) ([]models.BlogPost, error)
+	// StreamBlogPosts calls fn with every post, in the order of
+	// GetBlogPosts, as it is read, without holding them all in memory. It
+	// stops at the first error of fn and returns it as is.
+	StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) error
+	// UpdateBlogPost replaces the post with post.ID and sets CreatedAt and
+	// UpdatedAt of post to the stored values.
+	UpdateBlogPost(ctx context.Context, post *models.BlogPost) error
+	// UpsertBlogPost replaces the post with blog.ID, or creates it when there
+	// is none, and reports whether it was created. CreatedAt and UpdatedAt
+	// are kept when set, so imported posts retain their timestamps; without
+	// them an existing post keeps its creation time and is updated now.
+	// CreatedAt and UpdatedAt of blog are set to the stored values.
+	UpsertBlogPost(ctx context.Context, blog *models.BlogPost) (created bool, err error)
+	DeleteBlogPost(ctx context.Context, ID *uuid.UUID) error
 }
 
 type blogPostDomain struct {
-	db *sql.DB
+	db       dbtx
+	replicas *Replicas
 }
 
 // NewBlogPostDomain returns a new BlogPostDomain.
@@ -28,6 +47,13 @@ func NewBlogPostDomain(db *sql.DB) BlogPostDomain {
 	return &blogPostDomain{db: db}
 }
 
+// NewReplicatedBlogPostDomain returns a BlogPostDomain that writes to
+// primary and reads from replicas, unless the context asks for primary reads.
+// Transactions run on primary only.
+func NewReplicatedBlogPostDomain(primary *sql.DB, replicas *Replicas) BlogPostDomain {
+	return &blogPostDomain{db: primary, replicas: replicas}
+}
+
 var (
 	ErrorBlogPostNotFound     = errors.New("blog post not found")
 	ErrorGetBlogPostFailed    = errors.New("failed to get blog post")
@@ -35,97 +61,317 @@ var (
 	ErrorCreateBlogPostFailed = errors.New("failed to create blog post")
 	ErrorUpdateBlogPostFailed = errors.New("failed to update blog post")
 	ErrorDeleteBlogPostFailed = errors.New("failed to delete blog post")
+	ErrorUpsertBlogPostFailed = errors.New("failed to save blog post")
+	ErrorSlugTaken            = errors.New("the slug is taken by another blog post")
+	ErrorTimeout              = errors.New("the database did not respond in time")
 )
 
-func (d *blogPostDomain) CreateBlogPost(blog *models.BlogPost) (*uuid.UUID, error) {
+// errorCodes are the stable, machine-readable codes of the Error* sentinels.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrorBlogPostNotFound, "blog_post_not_found"},
+	{ErrorGetBlogPostFailed, "get_blog_post_failed"},
+	{ErrorGetBlogPostsFailed, "get_blog_posts_failed"},
+	{ErrorCreateBlogPostFailed, "create_blog_post_failed"},
+	{ErrorUpdateBlogPostFailed, "update_blog_post_failed"},
+	{ErrorDeleteBlogPostFailed, "delete_blog_post_failed"},
+	{ErrorUpsertBlogPostFailed, "upsert_blog_post_failed"},
+	{ErrorSlugTaken, "slug_taken"},
+	{ErrorIdempotencyKeyFailed, "idempotency_key_failed"},
+	{ErrorTransactionFailed, "transaction_failed"},
+	{ErrorTimeout, "timeout"},
+}
+
+// ErrorCode returns the code of the Error* sentinel err matches, or
+// "internal" for any other error.
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "internal"
+}
+
+// Message returns the message of the Error* sentinel err matches, which is
+// safe to show to clients unlike the database error err may wrap.
+func Message(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.err.Error()
+		}
+	}
+	return "internal error"
+}
+
+// dbError wraps err, returned by a database call, in ErrorTimeout when ctx
+// hit its deadline, and in sentinel otherwise.
+func dbError(ctx context.Context, sentinel, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		sentinel = ErrorTimeout
+	}
+	if err == nil {
+		return sentinel
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+// writeError is dbError for statements that store a slug, returning
+// ErrorSlugTaken when err is a violation of the unique index on slugs.
+func writeError(ctx context.Context, sentinel, err error) error {
+	if isSlugConflict(err) {
+		sentinel = ErrorSlugTaken
+	}
+	return dbError(ctx, sentinel, err)
+}
+
+// isSlugConflict reports whether err, or an error it wraps, is Postgres or
+// SQLite rejecting a slug that the blog_posts_slug_key index already holds.
+func isSlugConflict(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && state.SQLState() == "23505" {
+		return strings.Contains(err.Error(), "blog_posts_slug_key")
+	}
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) && coded.Code() == 2067 { // SQLITE_CONSTRAINT_UNIQUE
+		return strings.Contains(err.Error(), "blog_posts.slug")
+	}
+	return false
+}
+
+const blogPostColumns = `id, title, description, body, COALESCE(slug, ''), tags, draft, created_at, updated_at`
+
+// CreateBlogPost inserts a new blog post. ID, CreatedAt and UpdatedAt are kept
+// when already set, so imported posts retain their original identity.
+func (d *blogPostDomain) CreateBlogPost(ctx context.Context, blog *models.BlogPost) (_ *uuid.UUID, err error) {
 	var ID *uuid.UUID
 	query := `
-       INSERT INTO blog_posts (title, description, body, created_at, updated_at)
-       VALUES ($1, $2, $3, $4, $5)
+       INSERT INTO blog_posts (id, title, description, body, slug, tags, draft, created_at, updated_at)
+       VALUES (COALESCE($1, uuid_generate_v4()), $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
        RETURNING id
     `
-	now := time.Now()
-	err := d.db.QueryRow(query, blog.Title, blog.Description, blog.Body, now, now).
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, "CreateBlogPost", query)
+	defer endStatement(span, &err)
+
+	now := time.Now().UTC()
+	createdAt, updatedAt := timestampOr(blog.CreatedAt, now), timestampOr(blog.UpdatedAt, now)
+	err = d.db.QueryRowContext(ctx, query, blog.ID, blog.Title, blog.Description, blog.Body, blog.Slug, pq.Array(tagsOrEmpty(blog.Tags)), blog.Draft, createdAt, updatedAt).
 		Scan(&ID)
 	if err != nil {
-		return nil, ErrorCreateBlogPostFailed
+		return nil, writeError(ctx, ErrorCreateBlogPostFailed, err)
 	}
 	return ID, nil
 }
 
-func (d *blogPostDomain) GetBlogPost(ID *uuid.UUID) (*models.BlogPost, error) {
+func (d *blogPostDomain) GetBlogPost(ctx context.Context, ID *uuid.UUID) (*models.BlogPost, error) {
 	query := `
-       SELECT id, title, description, body, created_at, updated_at
+       SELECT ` + blogPostColumns + `
        FROM blog_posts
        WHERE id = $1
     `
+	return d.getBlogPost(ctx, "GetBlogPost", query, ID)
+}
+
+func (d *blogPostDomain) GetBlogPostBySlug(ctx context.Context, slug string) (*models.BlogPost, error) {
+	query := `
+       SELECT ` + blogPostColumns + `
+       FROM blog_posts
+       WHERE slug = $1
+    `
+	return d.getBlogPost(ctx, "GetBlogPostBySlug", query, slug)
+}
+
+// getBlogPost runs query, the statement called name, for a single post.
+func (d *blogPostDomain) getBlogPost(ctx context.Context, name, query string, arg any) (_ *models.BlogPost, err error) {
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, name, query)
+	defer endStatement(span, &err)
 
 	var blog models.BlogPost
-	err := d.db.QueryRow(query, ID).
-		Scan(&blog.ID, &blog.Title, &blog.Description, &blog.Body, &blog.CreatedAt, &blog.UpdatedAt)
-	if err == sql.ErrNoRows {
-		return nil, ErrorBlogPostNotFound
-	} else if err != nil {
-		return nil, ErrorGetBlogPostFailed
+	err = d.replicas.read(ctx, d.db, func(db dbtx) error {
+		err := scanBlogPost(db.QueryRowContext(ctx, query, arg), &blog)
+		if err == sql.ErrNoRows {
+			return ErrorBlogPostNotFound
+		} else if err != nil {
+			return dbError(ctx, ErrorGetBlogPostFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &blog, nil
 }
 
-func (d *blogPostDomain) GetBlogPosts() ([]models.BlogPost, error) {
+func (d *blogPostDomain) GetBlogPosts(ctx context.Context) (_ []models.BlogPost, err error) {
 	query := `
-       SELECT id, title, description, body, created_at, updated_at
+       SELECT ` + blogPostColumns + `
        FROM blog_posts
        ORDER BY created_at DESC
     `
-	rows, err := d.db.Query(query)
-	if err != nil {
-		fmt.Println(err.Error())
-		return nil, ErrorGetBlogPostsFailed
-	}
-	defer rows.Close()
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, "GetBlogPosts", query)
+	defer endStatement(span, &err)
 
-	blogs := []models.BlogPost{}
-	for rows.Next() {
-		var blog models.BlogPost
-		if err := rows.Scan(&blog.ID, &blog.Title, &blog.Description, &blog.Body, &blog.CreatedAt, &blog.UpdatedAt); err != nil {
-			fmt.Println(err.Error())
-			return nil, ErrorGetBlogPostsFailed
+	var blogs []models.BlogPost
+	err = d.replicas.read(ctx, d.db, func(db dbtx) error {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return dbError(ctx, ErrorGetBlogPostsFailed, err)
 		}
-		blogs = append(blogs, blog)
-	}
-	if err := rows.Err(); err != nil {
-		fmt.Println(err.Error())
-		return nil, ErrorGetBlogPostsFailed
+		defer rows.Close()
+
+		blogs = []models.BlogPost{}
+		for rows.Next() {
+			var blog models.BlogPost
+			if err := scanBlogPost(rows, &blog); err != nil {
+				return dbError(ctx, ErrorGetBlogPostsFailed, err)
+			}
+			blogs = append(blogs, blog)
+		}
+		if err := rows.Err(); err != nil {
+			return dbError(ctx, ErrorGetBlogPostsFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return blogs, nil
 }
 
-func (d *blogPostDomain) UpdateBlogPost(blog *models.BlogPost) error {
+func (d *blogPostDomain) StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) (err error) {
+	query := `
+       SELECT ` + blogPostColumns + `
+       FROM blog_posts
+       ORDER BY created_at DESC
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, "StreamBlogPosts", query)
+	defer endStatement(span, &err)
+
+	return d.replicas.read(ctx, d.db, func(db dbtx) error {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return dbError(ctx, ErrorGetBlogPostsFailed, err)
+		}
+		defer rows.Close()
+
+		// Once fn has seen a post, reading again from the primary would
+		// hand it the same posts twice.
+		streamed := false
+		for rows.Next() {
+			var blog models.BlogPost
+			if err := scanBlogPost(rows, &blog); err != nil {
+				return abortReadIf(streamed, dbError(ctx, ErrorGetBlogPostsFailed, err))
+			}
+			streamed = true
+			if err := fn(&blog); err != nil {
+				return &abortedRead{err: err}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return abortReadIf(streamed, dbError(ctx, ErrorGetBlogPostsFailed, err))
+		}
+		return nil
+	})
+}
+
+func (d *blogPostDomain) UpdateBlogPost(ctx context.Context, blog *models.BlogPost) (err error) {
 	query := `
        UPDATE blog_posts
-       SET title = $1, description = $2, body = $3, updated_at = $4
-       WHERE id = $5
+       SET title = $1, description = $2, body = $3, slug = NULLIF($4, ''), tags = $5, draft = $6, updated_at = $7
+       WHERE id = $8
+       RETURNING created_at, updated_at
     `
-	now := time.Now()
-	_, err := d.db.Exec(query, blog.Title, blog.Description, blog.Body, now, blog.ID)
-	if err != nil {
-		return ErrorUpdateBlogPostFailed
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, "UpdateBlogPost", query)
+	defer endStatement(span, &err)
+
+	now := time.Now().UTC()
+	err = d.db.QueryRowContext(ctx, query, blog.Title, blog.Description, blog.Body, blog.Slug, pq.Array(tagsOrEmpty(blog.Tags)), blog.Draft, now, blog.ID).
+		Scan(&blog.CreatedAt, &blog.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrorBlogPostNotFound
+	} else if err != nil {
+		return writeError(ctx, ErrorUpdateBlogPostFailed, err)
 	}
+	blog.CreatedAt, blog.UpdatedAt = blog.CreatedAt.UTC(), blog.UpdatedAt.UTC()
 	return nil
 }
 
-func (d *blogPostDomain) DeleteBlogPost(ID *uuid.UUID) error {
+func (d *blogPostDomain) UpsertBlogPost(ctx context.Context, blog *models.BlogPost) (_ bool, err error) {
+	if blog.ID == nil {
+		return false, ErrorUpsertBlogPostFailed
+	}
+	// xmax is 0 for a row version the statement inserted, and set for one it
+	// updated.
+	query := `
+       INSERT INTO blog_posts (id, title, description, body, slug, tags, draft, created_at, updated_at)
+       VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+       ON CONFLICT (id) DO UPDATE
+       SET title = EXCLUDED.title, description = EXCLUDED.description, body = EXCLUDED.body,
+           slug = EXCLUDED.slug, tags = EXCLUDED.tags, draft = EXCLUDED.draft,
+           created_at = CASE WHEN $10 THEN EXCLUDED.created_at ELSE blog_posts.created_at END,
+           updated_at = EXCLUDED.updated_at
+       RETURNING created_at, updated_at, xmax = 0
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, "UpsertBlogPost", query)
+	defer endStatement(span, &err)
+
+	now := time.Now().UTC()
+	createdAt, updatedAt := timestampOr(blog.CreatedAt, now), timestampOr(blog.UpdatedAt, now)
+	var created bool
+	err = d.db.QueryRowContext(ctx, query, blog.ID, blog.Title, blog.Description, blog.Body, blog.Slug, pq.Array(tagsOrEmpty(blog.Tags)), blog.Draft,
+		createdAt, updatedAt, !blog.CreatedAt.IsZero()).
+		Scan(&blog.CreatedAt, &blog.UpdatedAt, &created)
+	if err != nil {
+		return false, writeError(ctx, ErrorUpsertBlogPostFailed, err)
+	}
+	blog.CreatedAt, blog.UpdatedAt = blog.CreatedAt.UTC(), blog.UpdatedAt.UTC()
+	return created, nil
+}
+
+func (d *blogPostDomain) DeleteBlogPost(ctx context.Context, ID *uuid.UUID) (err error) {
 	query := `DELETE FROM blog_posts WHERE id = $1`
-	result, err := d.db.Exec(query, ID)
+	ctx, span := traceStatement(ctx, semconv.DBSystemPostgreSQL, "DeleteBlogPost", query)
+	defer endStatement(span, &err)
+
+	result, err := d.db.ExecContext(ctx, query, ID)
 	if err != nil {
-		return ErrorDeleteBlogPostFailed
+		return dbError(ctx, ErrorDeleteBlogPostFailed, err)
 	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return ErrorDeleteBlogPostFailed
+		return dbError(ctx, ErrorDeleteBlogPostFailed, err)
 	}
 	if rowsAffected == 0 {
 		return ErrorBlogPostNotFound
 	}
 	return nil
 }
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBlogPost(row rowScanner, blog *models.BlogPost) error {
+	err := row.Scan(&blog.ID, &blog.Title, &blog.Description, &blog.Body, &blog.Slug,
+		pq.Array(&blog.Tags), &blog.Draft, &blog.CreatedAt, &blog.UpdatedAt)
+	blog.CreatedAt, blog.UpdatedAt = blog.CreatedAt.UTC(), blog.UpdatedAt.UTC()
+	return err
+}
+
+// timestampOr returns t in UTC, or fallback when t is not set.
+func timestampOr(t, fallback time.Time) time.Time {
+	if t.IsZero() {
+		return fallback
+	}
+	return t.UTC()
+}
+
+// tagsOrEmpty keeps the tags column NOT NULL for posts without tags.
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
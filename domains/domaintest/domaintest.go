@@ -0,0 +1,535 @@
+// Package domaintest provides conformance suites that every BlogPostDomain,
+// IdempotencyKeyDomain and TxRunner implementation must pass, a suite for the
+// tracing of SQL-backed domains, and helpers for tests that run against a
+// shared database.
+package domaintest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+)
+
+// Factory returns an empty BlogPostDomain. It is called once per subtest and
+// should register any cleanup with t.Cleanup.
+type Factory func(t *testing.T) domains.BlogPostDomain
+
+// Run runs the conformance suite against the domains returned by newDomain.
+func Run(t *testing.T, newDomain Factory) {
+	tests := map[string]func(t *testing.T, d domains.BlogPostDomain){
+		"CreateAndGet":             testCreateAndGet,
+		"CreateKeepsIdentity":      testCreateKeepsIdentity,
+		"CreateDuplicateIDFails":   testCreateDuplicateIDFails,
+		"CreateDuplicateSlugFails": testCreateDuplicateSlugFails,
+		"GetNotFound":              testGetNotFound,
+		"GetBySlug":                testGetBySlug,
+		"ListEmpty":                testListEmpty,
+		"ListNewestFirst":          testListNewestFirst,
+		"StreamNewestFirst":        testStreamNewestFirst,
+		"StreamStopsOnError":       testStreamStopsOnError,
+		"Update":                   testUpdate,
+		"UpdateNotFound":           testUpdateNotFound,
+		"UpdateDuplicateSlugFails": testUpdateDuplicateSlugFails,
+		"UpsertCreates":            testUpsertCreates,
+		"UpsertReplaces":           testUpsertReplaces,
+		"UpsertKeepsTimestamps":    testUpsertKeepsTimestamps,
+		"UpsertDuplicateSlugFails": testUpsertDuplicateSlugFails,
+		"Delete":                   testDelete,
+		"DeleteNotFound":           testDeleteNotFound,
+		"ConcurrentCreates":        testConcurrentCreates,
+		"ConcurrentUpdates":        testConcurrentUpdates,
+		"ReturnedPostsAreDetached": testReturnedPostsAreDetached,
+		"ExpiredContextTimesOut":   testExpiredContextTimesOut,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newDomain(t))
+		})
+	}
+}
+
+var ctx = context.Background()
+
+func newPost(title string) *models.BlogPost {
+	return &models.BlogPost{
+		Title:       title,
+		Description: "Some description for the blog",
+		Body:        "Some body for the blog",
+	}
+}
+
+func mustCreate(t *testing.T, d domains.BlogPostDomain, post *models.BlogPost) *uuid.UUID {
+	t.Helper()
+	ID, err := d.CreateBlogPost(ctx, post)
+	if err != nil {
+		t.Fatalf("CreateBlogPost: %v", err)
+	}
+	if ID == nil {
+		t.Fatal("CreateBlogPost returned a nil ID")
+	}
+	return ID
+}
+
+func mustGet(t *testing.T, d domains.BlogPostDomain, ID *uuid.UUID) *models.BlogPost {
+	t.Helper()
+	post, err := d.GetBlogPost(ctx, ID)
+	if err != nil {
+		t.Fatalf("GetBlogPost: %v", err)
+	}
+	return post
+}
+
+func assertUTC(t *testing.T, name string, ts time.Time) {
+	t.Helper()
+	if ts.IsZero() {
+		t.Errorf("%s is not set", name)
+	}
+	if ts.Location() != time.UTC {
+		t.Errorf("%s is not in UTC: %v", name, ts)
+	}
+}
+
+func testCreateAndGet(t *testing.T, d domains.BlogPostDomain) {
+	post := newPost("Some title for blog")
+	post.Slug = "some-title"
+	post.Tags = []string{"go", "blog"}
+	post.Draft = true
+	before := time.Now().Add(-time.Minute)
+
+	ID := mustCreate(t, d, post)
+	got := mustGet(t, d, ID)
+
+	if *got.ID != *ID {
+		t.Errorf("unexpected ID: got %v want %v", got.ID, ID)
+	}
+	if got.Title != post.Title || got.Description != post.Description || got.Body != post.Body {
+		t.Errorf("unexpected content: %+v", got)
+	}
+	if got.Slug != post.Slug || !got.Draft || fmt.Sprint(got.Tags) != fmt.Sprint(post.Tags) {
+		t.Errorf("unexpected slug, tags or draft: %+v", got)
+	}
+	assertUTC(t, "created_at", got.CreatedAt)
+	assertUTC(t, "updated_at", got.UpdatedAt)
+	if got.CreatedAt.Before(before) {
+		t.Errorf("created_at %v is before the post was created", got.CreatedAt)
+	}
+}
+
+func testCreateKeepsIdentity(t *testing.T, d domains.BlogPostDomain) {
+	ID := uuid.New()
+	post := newPost("Imported post")
+	post.ID = &ID
+	post.CreatedAt = time.Date(2020, 1, 2, 4, 4, 5, 0, time.FixedZone("CET", 3600))
+	post.UpdatedAt = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if got := mustCreate(t, d, post); *got != ID {
+		t.Fatalf("client supplied ID was not kept: got %v want %v", got, ID)
+	}
+	got := mustGet(t, d, &ID)
+	assertUTC(t, "created_at", got.CreatedAt)
+	if !got.CreatedAt.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("created_at was not kept: %v", got.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("updated_at was not kept: %v", got.UpdatedAt)
+	}
+}
+
+func testCreateDuplicateIDFails(t *testing.T, d domains.BlogPostDomain) {
+	ID := mustCreate(t, d, newPost("First"))
+	duplicate := newPost("Second")
+	duplicate.ID = ID
+	if _, err := d.CreateBlogPost(ctx, duplicate); !errors.Is(err, domains.ErrorCreateBlogPostFailed) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testCreateDuplicateSlugFails(t *testing.T, d domains.BlogPostDomain) {
+	first := newPost("First")
+	first.Slug = "same"
+	mustCreate(t, d, first)
+
+	second := newPost("Second")
+	second.Slug = "same"
+	if _, err := d.CreateBlogPost(ctx, second); !errors.Is(err, domains.ErrorSlugTaken) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Posts without a slug never conflict.
+	mustCreate(t, d, newPost("Third"))
+	mustCreate(t, d, newPost("Fourth"))
+}
+
+func testGetNotFound(t *testing.T, d domains.BlogPostDomain) {
+	ID := uuid.New()
+	if _, err := d.GetBlogPost(ctx, &ID); !errors.Is(err, domains.ErrorBlogPostNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testGetBySlug(t *testing.T, d domains.BlogPostDomain) {
+	post := newPost("With slug")
+	post.Slug = "with-slug"
+	ID := mustCreate(t, d, post)
+
+	got, err := d.GetBlogPostBySlug(ctx, "with-slug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got.ID != *ID {
+		t.Errorf("unexpected post: %+v", got)
+	}
+	if _, err := d.GetBlogPostBySlug(ctx, "missing"); !errors.Is(err, domains.ErrorBlogPostNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testListEmpty(t *testing.T, d domains.BlogPostDomain) {
+	blogs, err := d.GetBlogPosts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blogs == nil || len(blogs) != 0 {
+		t.Errorf("expected an empty, non nil list: %#v", blogs)
+	}
+}
+
+func testListNewestFirst(t *testing.T, d domains.BlogPostDomain) {
+	dates := []time.Time{
+		time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for i, date := range dates {
+		post := newPost(fmt.Sprintf("Post %d", i))
+		post.CreatedAt = date
+		mustCreate(t, d, post)
+	}
+
+	blogs, err := d.GetBlogPosts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, blog := range blogs {
+		got = append(got, blog.Title)
+	}
+	if want := []string{"Post 1", "Post 2", "Post 0"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("unexpected order:\ngot  %v\nwant %v\n", got, want)
+	}
+}
+
+func testStreamNewestFirst(t *testing.T, d domains.BlogPostDomain) {
+	for i, year := range []int{2021, 2023, 2022} {
+		post := newPost(fmt.Sprintf("Post %d", i))
+		post.CreatedAt = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		post.Tags = []string{"streamed"}
+		mustCreate(t, d, post)
+	}
+
+	got := []string{}
+	err := d.StreamBlogPosts(ctx, func(blog *models.BlogPost) error {
+		if blog.ID == nil || blog.Body == "" || len(blog.Tags) != 1 {
+			t.Errorf("streamed an incomplete post: %+v", blog)
+		}
+		got = append(got, blog.Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Post 1", "Post 2", "Post 0"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("unexpected order:\ngot  %v\nwant %v\n", got, want)
+	}
+}
+
+func testStreamStopsOnError(t *testing.T, d domains.BlogPostDomain) {
+	mustCreate(t, d, newPost("First"))
+	mustCreate(t, d, newPost("Second"))
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := d.StreamBlogPosts(ctx, func(blog *models.BlogPost) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Errorf("StreamBlogPosts returned %v after %d calls, want the error of fn after 1", err, calls)
+	}
+}
+
+func testUpdate(t *testing.T, d domains.BlogPostDomain) {
+	post := newPost("Original")
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	post.CreatedAt = created
+	post.UpdatedAt = created
+	ID := mustCreate(t, d, post)
+
+	update := mustGet(t, d, ID)
+	update.Title = "Updated"
+	update.Body = "Updated body"
+	update.Tags = []string{"updated"}
+	if err := d.UpdateBlogPost(ctx, update); err != nil {
+		t.Fatal(err)
+	}
+
+	got := mustGet(t, d, ID)
+	if got.Title != "Updated" || got.Body != "Updated body" || fmt.Sprint(got.Tags) != "[updated]" {
+		t.Errorf("update was not stored: %+v", got)
+	}
+	if got.Description != post.Description {
+		t.Errorf("unchanged field was modified: %+v", got)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("created_at changed on update: %v", got.CreatedAt)
+	}
+	if !got.UpdatedAt.After(created) {
+		t.Errorf("updated_at did not advance: %v", got.UpdatedAt)
+	}
+	if !update.CreatedAt.Equal(got.CreatedAt) || !update.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Errorf("update reported %v, %v, stored %v, %v", update.CreatedAt, update.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+}
+
+func testUpdateNotFound(t *testing.T, d domains.BlogPostDomain) {
+	ID := uuid.New()
+	post := newPost("Missing")
+	post.ID = &ID
+	if err := d.UpdateBlogPost(ctx, post); !errors.Is(err, domains.ErrorBlogPostNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testUpsertCreates(t *testing.T, d domains.BlogPostDomain) {
+	ID := uuid.New()
+	post := newPost("Upserted")
+	post.ID = &ID
+	post.Slug = "upserted"
+	post.Tags = []string{"sync"}
+
+	created, err := d.UpsertBlogPost(ctx, post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Error("upsert of a new ID did not report a create")
+	}
+	assertUTC(t, "created_at", post.CreatedAt)
+
+	got := mustGet(t, d, &ID)
+	if got.Title != "Upserted" || got.Slug != "upserted" || fmt.Sprint(got.Tags) != "[sync]" {
+		t.Errorf("upserted post was not stored: %+v", got)
+	}
+	if !got.CreatedAt.Equal(post.CreatedAt) || !got.UpdatedAt.Equal(post.UpdatedAt) {
+		t.Errorf("upsert reported timestamps %v, %v, stored %v, %v",
+			post.CreatedAt, post.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+}
+
+func testUpsertReplaces(t *testing.T, d domains.BlogPostDomain) {
+	post := newPost("Original")
+	post.Slug = "original"
+	post.Tags = []string{"old"}
+	post.Draft = true
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	post.CreatedAt = created
+	post.UpdatedAt = created
+	ID := mustCreate(t, d, post)
+
+	replacement := newPost("Replaced")
+	replacement.ID = ID
+	isCreate, err := d.UpsertBlogPost(ctx, replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isCreate {
+		t.Error("upsert of an existing ID reported a create")
+	}
+
+	got := mustGet(t, d, ID)
+	if got.Title != "Replaced" || got.Slug != "" || len(got.Tags) != 0 || got.Draft {
+		t.Errorf("post was not fully replaced: %+v", got)
+	}
+	if !got.CreatedAt.Equal(created) || !replacement.CreatedAt.Equal(created) {
+		t.Errorf("created_at changed on upsert: stored %v, reported %v", got.CreatedAt, replacement.CreatedAt)
+	}
+	if !got.UpdatedAt.After(created) {
+		t.Errorf("updated_at did not advance: %v", got.UpdatedAt)
+	}
+}
+
+func testUpsertKeepsTimestamps(t *testing.T, d domains.BlogPostDomain) {
+	ID := mustCreate(t, d, newPost("Original"))
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	replacement := newPost("Imported")
+	replacement.ID = ID
+	replacement.CreatedAt = created
+	replacement.UpdatedAt = updated
+	if _, err := d.UpsertBlogPost(ctx, replacement); err != nil {
+		t.Fatal(err)
+	}
+
+	got := mustGet(t, d, ID)
+	if !got.CreatedAt.Equal(created) || !got.UpdatedAt.Equal(updated) {
+		t.Errorf("upsert did not keep the given timestamps: %v, %v", got.CreatedAt, got.UpdatedAt)
+	}
+	if !replacement.CreatedAt.Equal(created) || !replacement.UpdatedAt.Equal(updated) {
+		t.Errorf("upsert reported other timestamps: %v, %v", replacement.CreatedAt, replacement.UpdatedAt)
+	}
+}
+
+func testUpsertDuplicateSlugFails(t *testing.T, d domains.BlogPostDomain) {
+	taken := newPost("Taken")
+	taken.Slug = "taken"
+	mustCreate(t, d, taken)
+
+	ID := uuid.New()
+	post := newPost("Other")
+	post.ID = &ID
+	post.Slug = "taken"
+	if _, err := d.UpsertBlogPost(ctx, post); !errors.Is(err, domains.ErrorSlugTaken) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testUpdateDuplicateSlugFails(t *testing.T, d domains.BlogPostDomain) {
+	taken := newPost("Taken")
+	taken.Slug = "taken"
+	mustCreate(t, d, taken)
+
+	post := mustGet(t, d, mustCreate(t, d, newPost("Other")))
+	post.Slug = "taken"
+	if err := d.UpdateBlogPost(ctx, post); !errors.Is(err, domains.ErrorSlugTaken) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testDelete(t *testing.T, d domains.BlogPostDomain) {
+	ID := mustCreate(t, d, newPost("To delete"))
+	other := mustCreate(t, d, newPost("To keep"))
+
+	if err := d.DeleteBlogPost(ctx, ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.GetBlogPost(ctx, ID); !errors.Is(err, domains.ErrorBlogPostNotFound) {
+		t.Errorf("deleted post is still found: %v", err)
+	}
+	mustGet(t, d, other)
+}
+
+func testDeleteNotFound(t *testing.T, d domains.BlogPostDomain) {
+	ID := uuid.New()
+	if err := d.DeleteBlogPost(ctx, &ID); !errors.Is(err, domains.ErrorBlogPostNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testConcurrentCreates(t *testing.T, d domains.BlogPostDomain) {
+	const n = 20
+	var wg sync.WaitGroup
+	IDs := make(chan uuid.UUID, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ID, err := d.CreateBlogPost(ctx, newPost(fmt.Sprintf("Concurrent %d", i)))
+			if err != nil {
+				t.Errorf("CreateBlogPost: %v", err)
+				return
+			}
+			IDs <- *ID
+		}(i)
+	}
+	wg.Wait()
+	close(IDs)
+
+	seen := map[uuid.UUID]bool{}
+	for ID := range IDs {
+		if seen[ID] {
+			t.Errorf("ID %v was handed out twice", ID)
+		}
+		seen[ID] = true
+	}
+	if blogs, err := d.GetBlogPosts(ctx); err != nil || len(blogs) != n {
+		t.Errorf("expected %d posts, got %d (%v)", n, len(blogs), err)
+	}
+}
+
+func testConcurrentUpdates(t *testing.T, d domains.BlogPostDomain) {
+	ID := mustCreate(t, d, newPost("Original"))
+
+	const n = 20
+	titles := map[string]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		title := fmt.Sprintf("Title %d", i)
+		titles[title] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			post := newPost(title)
+			post.ID = ID
+			if err := d.UpdateBlogPost(ctx, post); err != nil {
+				t.Errorf("UpdateBlogPost: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := mustGet(t, d, ID); !titles[got.Title] {
+		t.Errorf("final title %q was not written by any update", got.Title)
+	}
+}
+
+func testReturnedPostsAreDetached(t *testing.T, d domains.BlogPostDomain) {
+	post := newPost("Detached")
+	post.Tags = []string{"original"}
+	ID := mustCreate(t, d, post)
+	post.Tags[0] = "changed by caller"
+
+	got := mustGet(t, d, ID)
+	got.Tags[0] = "changed again"
+	got.Title = "Changed title"
+
+	if stored := mustGet(t, d, ID); stored.Tags[0] != "original" || stored.Title != "Detached" {
+		t.Errorf("stored post shares memory with the caller: %+v", stored)
+	}
+}
+
+func testExpiredContextTimesOut(t *testing.T, d domains.BlogPostDomain) {
+	ID := mustCreate(t, d, newPost("Existing"))
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := d.CreateBlogPost(expired, newPost("Late")); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("CreateBlogPost: unexpected error: %v", err)
+	}
+	if _, err := d.GetBlogPost(expired, ID); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("GetBlogPost: unexpected error: %v", err)
+	}
+	if _, err := d.GetBlogPosts(expired); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("GetBlogPosts: unexpected error: %v", err)
+	}
+	err := d.StreamBlogPosts(expired, func(blog *models.BlogPost) error { return nil })
+	if !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("StreamBlogPosts: unexpected error: %v", err)
+	}
+	if _, err := d.UpsertBlogPost(expired, &models.BlogPost{ID: ID, Title: "Late"}); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("UpsertBlogPost: unexpected error: %v", err)
+	}
+	if err := d.DeleteBlogPost(expired, ID); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("DeleteBlogPost: unexpected error: %v", err)
+	}
+	mustGet(t, d, ID)
+}
@@ -0,0 +1,160 @@
+package domaintest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+// IdempotencyKeyFactory returns an empty IdempotencyKeyDomain, like Factory.
+type IdempotencyKeyFactory func(t *testing.T) domains.IdempotencyKeyDomain
+
+// RunIdempotencyKeys runs the conformance suite of IdempotencyKeyDomain
+// against the domains returned by newDomain.
+func RunIdempotencyKeys(t *testing.T, newDomain IdempotencyKeyFactory) {
+	tests := map[string]func(t *testing.T, d domains.IdempotencyKeyDomain){
+		"ReserveNew":             testReserveNew,
+		"ReserveInProgress":      testReserveInProgress,
+		"ReserveCompleted":       testReserveCompleted,
+		"ReserveExpired":         testReserveExpired,
+		"Release":                testRelease,
+		"DeleteExpired":          testDeleteExpired,
+		"ExpiredContextTimesOut": testIdempotencyExpiredContextTimesOut,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newDomain(t))
+		})
+	}
+}
+
+// keyEpoch is a fixed time to create keys at, truncated to what every
+// storage keeps.
+var keyEpoch = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newKey(key, hash string, createdAt time.Time) *models.IdempotencyKey {
+	return &models.IdempotencyKey{
+		Key:         key,
+		RequestHash: hash,
+		CreatedAt:   createdAt,
+		ExpiresAt:   createdAt.Add(time.Hour),
+	}
+}
+
+func mustReserve(t *testing.T, d domains.IdempotencyKeyDomain, key *models.IdempotencyKey) *models.IdempotencyKey {
+	t.Helper()
+	existing, err := d.ReserveIdempotencyKey(ctx, key)
+	if err != nil {
+		t.Fatalf("ReserveIdempotencyKey: %v", err)
+	}
+	return existing
+}
+
+func testReserveNew(t *testing.T, d domains.IdempotencyKeyDomain) {
+	if existing := mustReserve(t, d, newKey("k", "hash", keyEpoch)); existing != nil {
+		t.Errorf("ReserveIdempotencyKey of a new key returned %+v", existing)
+	}
+}
+
+func testReserveInProgress(t *testing.T, d domains.IdempotencyKeyDomain) {
+	mustReserve(t, d, newKey("k", "hash", keyEpoch))
+
+	existing := mustReserve(t, d, newKey("k", "other", keyEpoch.Add(time.Minute)))
+	if existing == nil {
+		t.Fatal("ReserveIdempotencyKey of a held key returned nil")
+	}
+	if existing.RequestHash != "hash" || existing.Status != 0 || existing.Response != nil {
+		t.Errorf("unexpected key: %+v", existing)
+	}
+	if !existing.CreatedAt.Equal(keyEpoch) || !existing.ExpiresAt.Equal(keyEpoch.Add(time.Hour)) {
+		t.Errorf("unexpected timestamps: %v, %v", existing.CreatedAt, existing.ExpiresAt)
+	}
+}
+
+func testReserveCompleted(t *testing.T, d domains.IdempotencyKeyDomain) {
+	mustReserve(t, d, newKey("k", "hash", keyEpoch))
+	response := []byte(`{"id":"1"}`)
+	if err := d.CompleteIdempotencyKey(ctx, "k", 201, "application/json", response); err != nil {
+		t.Fatalf("CompleteIdempotencyKey: %v", err)
+	}
+	response[0] = 'x'
+
+	existing := mustReserve(t, d, newKey("k", "hash", keyEpoch.Add(time.Minute)))
+	if existing == nil {
+		t.Fatal("ReserveIdempotencyKey of a completed key returned nil")
+	}
+	if existing.Status != 201 || existing.ContentType != "application/json" || !bytes.Equal(existing.Response, []byte(`{"id":"1"}`)) {
+		t.Errorf("unexpected key: %+v", existing)
+	}
+}
+
+func testReserveExpired(t *testing.T, d domains.IdempotencyKeyDomain) {
+	mustReserve(t, d, newKey("k", "hash", keyEpoch))
+	if err := d.CompleteIdempotencyKey(ctx, "k", 201, "application/json", []byte(`{}`)); err != nil {
+		t.Fatalf("CompleteIdempotencyKey: %v", err)
+	}
+
+	later := keyEpoch.Add(time.Hour)
+	if existing := mustReserve(t, d, newKey("k", "other", later)); existing != nil {
+		t.Fatalf("ReserveIdempotencyKey of an expired key returned %+v", existing)
+	}
+	existing := mustReserve(t, d, newKey("k", "third", later))
+	if existing == nil || existing.RequestHash != "other" || existing.Status != 0 || existing.Response != nil {
+		t.Errorf("expired key was not taken over: %+v", existing)
+	}
+}
+
+func testRelease(t *testing.T, d domains.IdempotencyKeyDomain) {
+	mustReserve(t, d, newKey("k", "hash", keyEpoch))
+	if err := d.ReleaseIdempotencyKey(ctx, "k"); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey: %v", err)
+	}
+	if existing := mustReserve(t, d, newKey("k", "other", keyEpoch)); existing != nil {
+		t.Errorf("ReserveIdempotencyKey of a released key returned %+v", existing)
+	}
+	if err := d.ReleaseIdempotencyKey(ctx, "missing"); err != nil {
+		t.Errorf("ReleaseIdempotencyKey of a missing key: %v", err)
+	}
+}
+
+func testDeleteExpired(t *testing.T, d domains.IdempotencyKeyDomain) {
+	mustReserve(t, d, newKey("old", "hash", keyEpoch))
+	mustReserve(t, d, newKey("new", "hash", keyEpoch.Add(time.Minute)))
+
+	deleted, err := d.DeleteExpiredIdempotencyKeys(ctx, keyEpoch.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteExpiredIdempotencyKeys: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 deleted key, got %d", deleted)
+	}
+	if existing := mustReserve(t, d, newKey("new", "other", keyEpoch)); existing == nil {
+		t.Error("unexpired key was deleted")
+	}
+	if existing := mustReserve(t, d, newKey("old", "other", keyEpoch)); existing != nil {
+		t.Errorf("expired key was kept: %+v", existing)
+	}
+}
+
+func testIdempotencyExpiredContextTimesOut(t *testing.T, d domains.IdempotencyKeyDomain) {
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := d.ReserveIdempotencyKey(expired, newKey("k", "hash", keyEpoch)); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("ReserveIdempotencyKey: unexpected error: %v", err)
+	}
+	if err := d.CompleteIdempotencyKey(expired, "k", 201, "", nil); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("CompleteIdempotencyKey: unexpected error: %v", err)
+	}
+	if err := d.ReleaseIdempotencyKey(expired, "k"); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("ReleaseIdempotencyKey: unexpected error: %v", err)
+	}
+	if _, err := d.DeleteExpiredIdempotencyKeys(expired, keyEpoch); !errors.Is(err, domains.ErrorTimeout) {
+		t.Errorf("DeleteExpiredIdempotencyKeys: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package domaintest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/tracing/tracingtest"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// RunTracing checks that the SQL-backed domains returned by newDomain trace
+// every statement in a span of its own.
+func RunTracing(t *testing.T, newDomain Factory) {
+	tests := map[string]func(t *testing.T, d domains.BlogPostDomain){
+		"StatementSpans":      testStatementSpans,
+		"FailedStatementSpan": testFailedStatementSpan,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newDomain(t))
+		})
+	}
+}
+
+func testStatementSpans(t *testing.T, d domains.BlogPostDomain) {
+	recorder := tracingtest.Record(t)
+	parentCtx, parent := otel.Tracer("domaintest").Start(ctx, "request")
+
+	post := newPost("A secret title")
+	if _, err := d.CreateBlogPost(parentCtx, post); err != nil {
+		t.Fatalf("CreateBlogPost: %v", err)
+	}
+	missing := uuid.New()
+	if _, err := d.GetBlogPost(parentCtx, &missing); err == nil {
+		t.Fatal("GetBlogPost found a missing post")
+	}
+	if _, err := d.GetBlogPosts(parentCtx); err != nil {
+		t.Fatalf("GetBlogPosts: %v", err)
+	}
+	parent.End()
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		if span.Name() == "request" {
+			continue
+		}
+		names = append(names, span.Name())
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the request", span.Name())
+		}
+		if span.SpanKind() != oteltrace.SpanKindClient || span.Status().Code != codes.Unset {
+			t.Errorf("span %s has kind %v and status %v", span.Name(), span.SpanKind(), span.Status())
+		}
+		query := attributeValue(span, "db.query.text")
+		if query == "" || strings.Contains(query, post.Title) {
+			t.Errorf("span %s has query text %q", span.Name(), query)
+		}
+		if attributeValue(span, "db.operation.name") != span.Name() {
+			t.Errorf("span %s is not named after its statement", span.Name())
+		}
+	}
+	if got, want := strings.Join(names, " "), "CreateBlogPost GetBlogPost GetBlogPosts"; got != want {
+		t.Errorf("got spans %q, want %q", got, want)
+	}
+}
+
+func testFailedStatementSpan(t *testing.T, d domains.BlogPostDomain) {
+	recorder := tracingtest.Record(t)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := d.GetBlogPosts(canceled); err == nil {
+		t.Fatal("GetBlogPosts succeeded with a canceled context")
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error || len(spans[0].Events()) == 0 {
+		t.Fatalf("expected one span recording the error, got %v", spans)
+	}
+	if spans[0].Status().Description != domains.ErrorGetBlogPostsFailed.Error() {
+		t.Errorf("span status is %q", spans[0].Status().Description)
+	}
+}
+
+// attributeValue returns the attribute key of span as a string.
+func attributeValue(span trace.ReadOnlySpan, key attribute.Key) string {
+	attrs := attribute.NewSet(span.Attributes()...)
+	value, _ := attrs.Value(key)
+	return value.Emit()
+}
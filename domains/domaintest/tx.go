@@ -0,0 +1,309 @@
+package domaintest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+)
+
+// TxFactory returns a TxRunner over empty domains, like Factory.
+type TxFactory func(t *testing.T) domains.TxRunner
+
+// RunTx runs the conformance suite of TxRunner against the runners returned
+// by newRunner.
+func RunTx(t *testing.T, newRunner TxFactory) {
+	tests := map[string]func(t *testing.T, r domains.TxRunner){
+		"CommitsAcrossRepos":           testCommitsAcrossRepos,
+		"SeesOwnWrites":                testSeesOwnWrites,
+		"RollsBack":                    testRollsBack,
+		"RollsBackIdempotencyKeys":     testRollsBackIdempotencyKeys,
+		"ExpiredContextTimesOut":       testTxExpiredContextTimesOut,
+		"RetriesSerializationFailures": testRetriesSerializationFailures,
+		"GivesUpRetrying":              testGivesUpRetrying,
+		"DoesNotRetryOtherErrors":      testDoesNotRetryOtherErrors,
+		"RollbackRepos":                testRollbackRepos,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newRunner(t))
+		})
+	}
+}
+
+// errRollback makes the transaction of RollbackRepos roll back.
+var errRollback = errors.New("domaintest: rolled back")
+
+// RollbackRepos returns Repos bound to a transaction of runner that is
+// rolled back once t has finished, so a test leaves a shared database as it
+// found it. The transaction stays open for the whole test, so the test must
+// not use domains outside it.
+func RollbackRepos(t *testing.T, runner domains.TxRunner) domains.Repos {
+	t.Helper()
+	repos := make(chan domains.Repos)
+	done := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- runner.WithTx(ctx, func(tx domains.Repos) error {
+			repos <- tx
+			<-done
+			return errRollback
+		})
+	}()
+
+	select {
+	case tx := <-repos:
+		t.Cleanup(func() {
+			close(done)
+			if err := <-result; err != errRollback {
+				t.Errorf("rolling back: %v", err)
+			}
+		})
+		return tx
+	case err := <-result:
+		t.Fatalf("WithTx: %v", err)
+		return domains.Repos{}
+	}
+}
+
+// serializationFailure looks like a Postgres serialization failure.
+type serializationFailure struct{}
+
+func (serializationFailure) Error() string    { return "could not serialize access" }
+func (serializationFailure) SQLState() string { return "40001" }
+
+func countPosts(t *testing.T, r domains.TxRunner) int {
+	t.Helper()
+	var n int
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		posts, err := tx.BlogPosts.GetBlogPosts(ctx)
+		n = len(posts)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("GetBlogPosts: %v", err)
+	}
+	return n
+}
+
+func testCommitsAcrossRepos(t *testing.T, r domains.TxRunner) {
+	key := &models.IdempotencyKey{Key: "k", RequestHash: "hash", CreatedAt: keyEpoch, ExpiresAt: time.Now().Add(time.Hour)}
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		if _, err := tx.BlogPosts.CreateBlogPost(ctx, newPost("Created")); err != nil {
+			return err
+		}
+		_, err := tx.IdempotencyKeys.ReserveIdempotencyKey(ctx, key)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	if n := countPosts(t, r); n != 1 {
+		t.Errorf("expected 1 post, got %d", n)
+	}
+	err = r.WithTx(ctx, func(tx domains.Repos) error {
+		existing, err := tx.IdempotencyKeys.ReserveIdempotencyKey(ctx, key)
+		if err == nil && existing == nil {
+			t.Error("idempotency key was not kept")
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+}
+
+func testSeesOwnWrites(t *testing.T, r domains.TxRunner) {
+	var existing *uuid.UUID
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		var err error
+		existing, err = tx.BlogPosts.CreateBlogPost(ctx, newPost("Existing"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	err = r.WithTx(ctx, func(tx domains.Repos) error {
+		created := mustCreate(t, tx.BlogPosts, newPost("Created"))
+		mustGet(t, tx.BlogPosts, created)
+		upserted := uuid.New()
+		if _, err := tx.BlogPosts.UpsertBlogPost(ctx, &models.BlogPost{ID: &upserted, Title: "Upserted"}); err != nil {
+			return err
+		}
+		mustGet(t, tx.BlogPosts, &upserted)
+		return tx.BlogPosts.DeleteBlogPost(ctx, existing)
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if n := countPosts(t, r); n != 2 {
+		t.Errorf("expected 2 posts, got %d", n)
+	}
+}
+
+func testRollsBack(t *testing.T, r domains.TxRunner) {
+	var existing *uuid.UUID
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		var err error
+		existing, err = tx.BlogPosts.CreateBlogPost(ctx, newPost("Existing"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	err = r.WithTx(ctx, func(tx domains.Repos) error {
+		if _, err := tx.BlogPosts.CreateBlogPost(ctx, newPost("Created")); err != nil {
+			return err
+		}
+		post := mustGet(t, tx.BlogPosts, existing)
+		post.Title = "Changed"
+		if err := tx.BlogPosts.UpdateBlogPost(ctx, post); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("WithTx returned %v, want the error of fn", err)
+	}
+
+	err = r.WithTx(ctx, func(tx domains.Repos) error {
+		posts, err := tx.BlogPosts.GetBlogPosts(ctx)
+		if err == nil && (len(posts) != 1 || posts[0].Title != "Existing") {
+			t.Errorf("changes of a rolled back transaction were kept: %+v", posts)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+}
+
+func testRollsBackIdempotencyKeys(t *testing.T, r domains.TxRunner) {
+	completed := &models.IdempotencyKey{Key: "completed", RequestHash: "hash", CreatedAt: keyEpoch, ExpiresAt: time.Now().Add(time.Hour)}
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		_, err := tx.IdempotencyKeys.ReserveIdempotencyKey(ctx, completed)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	reserved := &models.IdempotencyKey{Key: "reserved", RequestHash: "hash", CreatedAt: keyEpoch, ExpiresAt: time.Now().Add(time.Hour)}
+	err = r.WithTx(ctx, func(tx domains.Repos) error {
+		if _, err := tx.IdempotencyKeys.ReserveIdempotencyKey(ctx, reserved); err != nil {
+			return err
+		}
+		if err := tx.IdempotencyKeys.CompleteIdempotencyKey(ctx, completed.Key, 201, "application/json", []byte("{}")); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("WithTx returned %v, want the error of fn", err)
+	}
+
+	err = r.WithTx(ctx, func(tx domains.Repos) error {
+		existing, err := tx.IdempotencyKeys.ReserveIdempotencyKey(ctx, completed)
+		if err != nil {
+			return err
+		}
+		if existing == nil || existing.Status != 0 {
+			t.Errorf("completion of a rolled back transaction was kept: %+v", existing)
+		}
+		if existing, err = tx.IdempotencyKeys.ReserveIdempotencyKey(ctx, reserved); err != nil {
+			return err
+		}
+		if existing != nil {
+			t.Errorf("reservation of a rolled back transaction was kept: %+v", existing)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+}
+
+func testTxExpiredContextTimesOut(t *testing.T, r domains.TxRunner) {
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+
+	calls := 0
+	err := r.WithTx(expired, func(tx domains.Repos) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, domains.ErrorTimeout) || calls != 0 {
+		t.Errorf("WithTx returned %v after %d calls, want a timeout before fn runs", err, calls)
+	}
+}
+
+func testRetriesSerializationFailures(t *testing.T, r domains.TxRunner) {
+	calls := 0
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		calls++
+		if _, err := tx.BlogPosts.CreateBlogPost(ctx, newPost("Created")); err != nil {
+			return err
+		}
+		if calls == 1 {
+			return serializationFailure{}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("fn ran %d times, want 2", calls)
+	}
+	if n := countPosts(t, r); n != 1 {
+		t.Errorf("expected only the post of the second attempt, got %d posts", n)
+	}
+}
+
+func testGivesUpRetrying(t *testing.T, r domains.TxRunner) {
+	calls := 0
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		calls++
+		return serializationFailure{}
+	})
+	if !domains.IsSerializationFailure(err) {
+		t.Errorf("WithTx returned %v, want the serialization failure", err)
+	}
+	if calls != 3 {
+		t.Errorf("fn ran %d times, want 3", calls)
+	}
+}
+
+func testDoesNotRetryOtherErrors(t *testing.T, r domains.TxRunner) {
+	calls := 0
+	errAbort := errors.New("abort")
+	err := r.WithTx(ctx, func(tx domains.Repos) error {
+		calls++
+		return errAbort
+	})
+	if err != errAbort || calls != 1 {
+		t.Errorf("WithTx returned %v after %d calls, want the error of fn after 1", err, calls)
+	}
+}
+
+func testRollbackRepos(t *testing.T, r domains.TxRunner) {
+	t.Run("test", func(t *testing.T) {
+		repos := RollbackRepos(t, r)
+		mustCreate(t, repos.BlogPosts, newPost("Created"))
+		if posts, err := repos.BlogPosts.GetBlogPosts(ctx); err != nil || len(posts) != 1 {
+			t.Errorf("GetBlogPosts returned %d posts, %v", len(posts), err)
+		}
+	})
+	if n := countPosts(t, r); n != 0 {
+		t.Errorf("expected the post to be rolled back, got %d posts", n)
+	}
+}
@@ -0,0 +1,107 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+// IdempotencyKeyDomain stores the responses of requests sent with an
+// Idempotency-Key header, so a retried request gets the first response.
+type IdempotencyKeyDomain interface {
+	// ReserveIdempotencyKey claims key.Key for a request until key.ExpiresAt.
+	// When the key is held and has not expired at key.CreatedAt, nothing
+	// changes and the stored key is returned instead.
+	ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	// CompleteIdempotencyKey stores the response of the request holding key.
+	CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, response []byte) error
+	// ReleaseIdempotencyKey deletes key, so the request can be sent again.
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	// DeleteExpiredIdempotencyKeys deletes the keys that expired by now and
+	// returns how many there were.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+var ErrorIdempotencyKeyFailed = errors.New("failed to store idempotency key")
+
+// reserveAttempts bounds how often a reservation is retried when the key it
+// conflicted with is released before it could be read.
+const reserveAttempts = 3
+
+type idempotencyKeyDomain struct {
+	db dbtx
+}
+
+// NewIdempotencyKeyDomain returns an IdempotencyKeyDomain backed by Postgres.
+func NewIdempotencyKeyDomain(db *sql.DB) IdempotencyKeyDomain {
+	return &idempotencyKeyDomain{db: db}
+}
+
+func (d *idempotencyKeyDomain) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	// An expired key is taken over as if it did not exist.
+	reserve := `
+       INSERT INTO idempotency_keys (key, request_hash, created_at, expires_at)
+       VALUES ($1, $2, $3, $4)
+       ON CONFLICT (key) DO UPDATE
+       SET request_hash = EXCLUDED.request_hash, status = NULL, content_type = NULL, response = NULL,
+           created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+       WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+       RETURNING key
+    `
+	get := `
+       SELECT key, request_hash, COALESCE(status, 0), COALESCE(content_type, ''), response, created_at, expires_at
+       FROM idempotency_keys
+       WHERE key = $1
+    `
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		var reserved string
+		err := d.db.QueryRowContext(ctx, reserve, key.Key, key.RequestHash, key.CreatedAt, key.ExpiresAt).Scan(&reserved)
+		if err == nil {
+			return nil, nil
+		} else if err != sql.ErrNoRows {
+			return nil, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+		}
+
+		var existing models.IdempotencyKey
+		err = d.db.QueryRowContext(ctx, get, key.Key).Scan(&existing.Key, &existing.RequestHash, &existing.Status,
+			&existing.ContentType, &existing.Response, &existing.CreatedAt, &existing.ExpiresAt)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+		}
+		existing.CreatedAt, existing.ExpiresAt = existing.CreatedAt.UTC(), existing.ExpiresAt.UTC()
+		return &existing, nil
+	}
+	return nil, ErrorIdempotencyKeyFailed
+}
+
+func (d *idempotencyKeyDomain) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, response []byte) error {
+	query := `UPDATE idempotency_keys SET status = $1, content_type = $2, response = $3 WHERE key = $4`
+	if _, err := d.db.ExecContext(ctx, query, status, contentType, response, key); err != nil {
+		return dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	return nil
+}
+
+func (d *idempotencyKeyDomain) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key); err != nil {
+		return dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	return nil
+}
+
+func (d *idempotencyKeyDomain) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result, err := d.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,100 @@
+package domains
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+type memoryIdempotencyKeyDomain struct {
+	mu   sync.Mutex
+	keys map[string]models.IdempotencyKey
+}
+
+// NewMemoryIdempotencyKeyDomain returns an IdempotencyKeyDomain that keeps
+// keys in memory, for use with StorageMemory.
+func NewMemoryIdempotencyKeyDomain() IdempotencyKeyDomain {
+	return &memoryIdempotencyKeyDomain{keys: make(map[string]models.IdempotencyKey)}
+}
+
+// snapshot returns a copy of d sharing no memory with it, for a transaction
+// to work on. d.mu must be held.
+func (d *memoryIdempotencyKeyDomain) snapshot() *memoryIdempotencyKeyDomain {
+	tx := &memoryIdempotencyKeyDomain{keys: make(map[string]models.IdempotencyKey, len(d.keys))}
+	for k, stored := range d.keys {
+		stored.Response = slices.Clone(stored.Response)
+		tx.keys[k] = stored
+	}
+	return tx
+}
+
+func (d *memoryIdempotencyKeyDomain) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	if ctx.Err() != nil {
+		return nil, dbError(ctx, ErrorIdempotencyKeyFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if existing, ok := d.keys[key.Key]; ok && existing.ExpiresAt.After(key.CreatedAt) {
+		existing.Response = slices.Clone(existing.Response)
+		return &existing, nil
+	}
+	d.keys[key.Key] = models.IdempotencyKey{
+		Key:         key.Key,
+		RequestHash: key.RequestHash,
+		CreatedAt:   key.CreatedAt.UTC(),
+		ExpiresAt:   key.ExpiresAt.UTC(),
+	}
+	return nil, nil
+}
+
+func (d *memoryIdempotencyKeyDomain) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, response []byte) error {
+	if ctx.Err() != nil {
+		return dbError(ctx, ErrorIdempotencyKeyFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if stored, ok := d.keys[key]; ok {
+		stored.Status = status
+		stored.ContentType = contentType
+		stored.Response = slices.Clone(response)
+		d.keys[key] = stored
+	}
+	return nil
+}
+
+func (d *memoryIdempotencyKeyDomain) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if ctx.Err() != nil {
+		return dbError(ctx, ErrorIdempotencyKeyFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.keys, key)
+	return nil
+}
+
+func (d *memoryIdempotencyKeyDomain) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	if ctx.Err() != nil {
+		return 0, dbError(ctx, ErrorIdempotencyKeyFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deleted int64
+	for k, stored := range d.keys {
+		if !stored.ExpiresAt.After(now) {
+			delete(d.keys, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,96 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+type sqliteIdempotencyKeyDomain struct {
+	db dbtx
+}
+
+// NewSQLiteIdempotencyKeyDomain returns an IdempotencyKeyDomain backed by
+// SQLite.
+func NewSQLiteIdempotencyKeyDomain(db *sql.DB) IdempotencyKeyDomain {
+	return &sqliteIdempotencyKeyDomain{db: db}
+}
+
+func (d *sqliteIdempotencyKeyDomain) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	// An expired key is taken over as if it did not exist.
+	reserve := `
+       INSERT INTO idempotency_keys (key, request_hash, created_at, expires_at)
+       VALUES ($1, $2, $3, $4)
+       ON CONFLICT (key) DO UPDATE
+       SET request_hash = excluded.request_hash, status = NULL, content_type = NULL, response = NULL,
+           created_at = excluded.created_at, expires_at = excluded.expires_at
+       WHERE idempotency_keys.expires_at <= excluded.created_at
+       RETURNING key
+    `
+	get := `
+       SELECT key, request_hash, COALESCE(status, 0), COALESCE(content_type, ''), response, created_at, expires_at
+       FROM idempotency_keys
+       WHERE key = $1
+    `
+	createdAt := key.CreatedAt.UTC().Format(sqliteTimeLayout)
+	expiresAt := key.ExpiresAt.UTC().Format(sqliteTimeLayout)
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		var reserved string
+		err := d.db.QueryRowContext(ctx, reserve, key.Key, key.RequestHash, createdAt, expiresAt).Scan(&reserved)
+		if err == nil {
+			return nil, nil
+		} else if err != sql.ErrNoRows {
+			return nil, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+		}
+
+		var existing models.IdempotencyKey
+		var existingCreatedAt, existingExpiresAt string
+		err = d.db.QueryRowContext(ctx, get, key.Key).Scan(&existing.Key, &existing.RequestHash, &existing.Status,
+			&existing.ContentType, &existing.Response, &existingCreatedAt, &existingExpiresAt)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+		}
+		if existing.CreatedAt, err = time.Parse(time.RFC3339Nano, existingCreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrorIdempotencyKeyFailed, err)
+		}
+		if existing.ExpiresAt, err = time.Parse(time.RFC3339Nano, existingExpiresAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrorIdempotencyKeyFailed, err)
+		}
+		existing.CreatedAt, existing.ExpiresAt = existing.CreatedAt.UTC(), existing.ExpiresAt.UTC()
+		return &existing, nil
+	}
+	return nil, ErrorIdempotencyKeyFailed
+}
+
+func (d *sqliteIdempotencyKeyDomain) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, response []byte) error {
+	query := `UPDATE idempotency_keys SET status = $1, content_type = $2, response = $3 WHERE key = $4`
+	if _, err := d.db.ExecContext(ctx, query, status, contentType, response, key); err != nil {
+		return dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	return nil
+}
+
+func (d *sqliteIdempotencyKeyDomain) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key); err != nil {
+		return dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	return nil
+}
+
+func (d *sqliteIdempotencyKeyDomain) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+	result, err := d.db.ExecContext(ctx, query, now.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return 0, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError(ctx, ErrorIdempotencyKeyFailed, err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,252 @@
+package domains
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+)
+
+type memoryBlogPost struct {
+	post models.BlogPost
+	seq  uint64
+}
+
+type memoryBlogPostDomain struct {
+	mu    sync.RWMutex
+	posts map[uuid.UUID]*memoryBlogPost
+	seq   uint64
+}
+
+// NewMemoryBlogPostDomain returns a BlogPostDomain that keeps posts in memory.
+// It is safe for concurrent use and is meant for local development and tests.
+// Operations never block, so ctx is only checked before they start.
+func NewMemoryBlogPostDomain() BlogPostDomain {
+	return &memoryBlogPostDomain{posts: map[uuid.UUID]*memoryBlogPost{}}
+}
+
+func (d *memoryBlogPostDomain) CreateBlogPost(ctx context.Context, blog *models.BlogPost) (*uuid.UUID, error) {
+	if ctx.Err() != nil {
+		return nil, dbError(ctx, ErrorCreateBlogPostFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ID := uuid.New()
+	if blog.ID != nil {
+		ID = *blog.ID
+	}
+	if _, ok := d.posts[ID]; ok {
+		return nil, ErrorCreateBlogPostFailed
+	}
+	if d.slugTaken(blog.Slug, ID) {
+		return nil, ErrorSlugTaken
+	}
+
+	now := time.Now().UTC()
+	post := copyBlogPost(blog)
+	post.ID = &ID
+	post.CreatedAt = timestampOr(post.CreatedAt, now)
+	post.UpdatedAt = timestampOr(post.UpdatedAt, now)
+
+	d.seq++
+	d.posts[ID] = &memoryBlogPost{post: post, seq: d.seq}
+	return &ID, nil
+}
+
+func (d *memoryBlogPostDomain) GetBlogPost(ctx context.Context, ID *uuid.UUID) (*models.BlogPost, error) {
+	if ctx.Err() != nil {
+		return nil, dbError(ctx, ErrorGetBlogPostFailed, ctx.Err())
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if ID == nil {
+		return nil, ErrorBlogPostNotFound
+	}
+	stored, ok := d.posts[*ID]
+	if !ok {
+		return nil, ErrorBlogPostNotFound
+	}
+	post := copyBlogPost(&stored.post)
+	return &post, nil
+}
+
+func (d *memoryBlogPostDomain) GetBlogPostBySlug(ctx context.Context, slug string) (*models.BlogPost, error) {
+	if ctx.Err() != nil {
+		return nil, dbError(ctx, ErrorGetBlogPostFailed, ctx.Err())
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, stored := range d.posts {
+		if slug != "" && stored.post.Slug == slug {
+			post := copyBlogPost(&stored.post)
+			return &post, nil
+		}
+	}
+	return nil, ErrorBlogPostNotFound
+}
+
+// GetBlogPosts returns every post, newest first. Posts created at the same
+// time are ordered by insertion, newest first.
+func (d *memoryBlogPostDomain) GetBlogPosts(ctx context.Context) ([]models.BlogPost, error) {
+	if ctx.Err() != nil {
+		return nil, dbError(ctx, ErrorGetBlogPostsFailed, ctx.Err())
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stored := make([]*memoryBlogPost, 0, len(d.posts))
+	for _, s := range d.posts {
+		stored = append(stored, s)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		if a, b := stored[i].post.CreatedAt, stored[j].post.CreatedAt; !a.Equal(b) {
+			return a.After(b)
+		}
+		return stored[i].seq > stored[j].seq
+	})
+
+	blogs := make([]models.BlogPost, 0, len(stored))
+	for _, s := range stored {
+		blogs = append(blogs, copyBlogPost(&s.post))
+	}
+	return blogs, nil
+}
+
+// StreamBlogPosts calls fn with a copy of the posts taken when it starts,
+// so fn may use the domain.
+func (d *memoryBlogPostDomain) StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) error {
+	blogs, err := d.GetBlogPosts(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		if err := fn(&blogs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memoryBlogPostDomain) UpdateBlogPost(ctx context.Context, blog *models.BlogPost) error {
+	if ctx.Err() != nil {
+		return dbError(ctx, ErrorUpdateBlogPostFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if blog.ID == nil {
+		return ErrorBlogPostNotFound
+	}
+	stored, ok := d.posts[*blog.ID]
+	if !ok {
+		return ErrorBlogPostNotFound
+	}
+	if d.slugTaken(blog.Slug, *blog.ID) {
+		return ErrorSlugTaken
+	}
+
+	post := copyBlogPost(blog)
+	post.CreatedAt = stored.post.CreatedAt
+	post.UpdatedAt = time.Now().UTC()
+	stored.post = post
+	blog.CreatedAt, blog.UpdatedAt = post.CreatedAt, post.UpdatedAt
+	return nil
+}
+
+func (d *memoryBlogPostDomain) UpsertBlogPost(ctx context.Context, blog *models.BlogPost) (bool, error) {
+	if ctx.Err() != nil {
+		return false, dbError(ctx, ErrorUpsertBlogPostFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if blog.ID == nil {
+		return false, ErrorUpsertBlogPostFailed
+	}
+	if d.slugTaken(blog.Slug, *blog.ID) {
+		return false, ErrorSlugTaken
+	}
+
+	now := time.Now().UTC()
+	post := copyBlogPost(blog)
+	post.CreatedAt = timestampOr(blog.CreatedAt, now)
+	post.UpdatedAt = timestampOr(blog.UpdatedAt, now)
+
+	stored, ok := d.posts[*blog.ID]
+	if ok {
+		if blog.CreatedAt.IsZero() {
+			post.CreatedAt = stored.post.CreatedAt
+		}
+		stored.post = post
+	} else {
+		d.seq++
+		d.posts[*blog.ID] = &memoryBlogPost{post: post, seq: d.seq}
+	}
+	blog.CreatedAt, blog.UpdatedAt = post.CreatedAt, post.UpdatedAt
+	return !ok, nil
+}
+
+func (d *memoryBlogPostDomain) DeleteBlogPost(ctx context.Context, ID *uuid.UUID) error {
+	if ctx.Err() != nil {
+		return dbError(ctx, ErrorDeleteBlogPostFailed, ctx.Err())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if ID == nil {
+		return ErrorBlogPostNotFound
+	}
+	if _, ok := d.posts[*ID]; !ok {
+		return ErrorBlogPostNotFound
+	}
+	delete(d.posts, *ID)
+	return nil
+}
+
+// snapshot returns a copy of d sharing no memory with it, for a transaction
+// to work on. d.mu must be held.
+func (d *memoryBlogPostDomain) snapshot() *memoryBlogPostDomain {
+	tx := &memoryBlogPostDomain{posts: make(map[uuid.UUID]*memoryBlogPost, len(d.posts)), seq: d.seq}
+	for ID, stored := range d.posts {
+		tx.posts[ID] = &memoryBlogPost{post: copyBlogPost(&stored.post), seq: stored.seq}
+	}
+	return tx
+}
+
+// slugTaken reports whether another post than ID already uses slug.
+func (d *memoryBlogPostDomain) slugTaken(slug string, ID uuid.UUID) bool {
+	if slug == "" {
+		return false
+	}
+	for otherID, stored := range d.posts {
+		if otherID != ID && stored.post.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// copyBlogPost returns a copy of blog that shares no memory with it.
+func copyBlogPost(blog *models.BlogPost) models.BlogPost {
+	post := *blog
+	if blog.ID != nil {
+		ID := *blog.ID
+		post.ID = &ID
+	}
+	post.Tags = slices.Clone(blog.Tags)
+	return post
+}
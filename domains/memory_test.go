@@ -0,0 +1,26 @@
+package domains_test
+
+import (
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/domains/domaintest"
+)
+
+func TestMemoryBlogPostDomain(t *testing.T) {
+	domaintest.Run(t, func(t *testing.T) domains.BlogPostDomain {
+		return domains.NewMemoryBlogPostDomain()
+	})
+}
+
+func TestMemoryIdempotencyKeyDomain(t *testing.T) {
+	domaintest.RunIdempotencyKeys(t, func(t *testing.T) domains.IdempotencyKeyDomain {
+		return domains.NewMemoryIdempotencyKeyDomain()
+	})
+}
+
+func TestMemoryTxRunner(t *testing.T) {
+	domaintest.RunTx(t, func(t *testing.T) domains.TxRunner {
+		return domains.NewMemoryTxRunner(domains.NewMemoryBlogPostDomain(), domains.NewMemoryIdempotencyKeyDomain())
+	})
+}
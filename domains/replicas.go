@@ -0,0 +1,145 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads returns a copy of ctx whose reads go to the primary
+// database, so a client sees its own recent writes despite replica lag.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether the reads of ctx must go to the primary.
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// Replicas spreads reads over read replicas round-robin. A replica is used
+// once it passes a health check, and dropped when it fails a check or a
+// read; reads go to the primary while no replica is healthy.
+type Replicas struct {
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// NewReplicas returns Replicas over dbs, none of them healthy until Check
+// or Monitor has run.
+func NewReplicas(dbs ...*sql.DB) *Replicas {
+	r := &Replicas{}
+	for _, db := range dbs {
+		r.replicas = append(r.replicas, &replica{db: db})
+	}
+	return r
+}
+
+// Check pings every replica, marking it healthy when it answers, and returns
+// how many do.
+func (r *Replicas) Check(ctx context.Context) int {
+	healthy := 0
+	for i, replica := range r.replicas {
+		err := replica.db.PingContext(ctx)
+		if err != nil && replica.healthy.Load() {
+			slog.WarnContext(ctx, "Read replica is down", "replica", i, "error", err)
+		}
+		replica.healthy.Store(err == nil)
+		if err == nil {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// Monitor checks the replicas right away and then every interval until ctx
+// is done, calling beat after each round.
+func (r *Replicas) Monitor(ctx context.Context, interval, timeout time.Duration, beat func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		r.Check(checkCtx)
+		cancel()
+		beat()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pick returns the next healthy replica, or nil when none is.
+func (r *Replicas) pick() *replica {
+	if r == nil {
+		return nil
+	}
+	for range r.replicas {
+		replica := r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+		if replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// abortedRead is returned by the fn of read to have read return err as it
+// is, without marking the replica down or running fn again.
+type abortedRead struct {
+	err error
+}
+
+func (e *abortedRead) Error() string { return e.err.Error() }
+func (e *abortedRead) Unwrap() error { return e.err }
+
+// abortReadIf returns err as an abortedRead when abort is set.
+func abortReadIf(abort bool, err error) error {
+	if abort {
+		return &abortedRead{err: err}
+	}
+	return err
+}
+
+// read runs fn against a healthy replica, or against primary when there is
+// none or ctx asks for primary reads. A replica whose read fails is marked
+// down and fn runs again against primary; finding no post is not a failure
+// of the replica, and neither is an abortedRead.
+func (r *Replicas) read(ctx context.Context, primary dbtx, fn func(db dbtx) error) error {
+	replica := r.pick()
+	if replica == nil || PrimaryReads(ctx) {
+		return unwrapAbortedRead(fn(primary))
+	}
+	err := fn(replica.db)
+	var aborted *abortedRead
+	if errors.As(err, &aborted) {
+		return aborted.err
+	}
+	if err == nil || errors.Is(err, ErrorBlogPostNotFound) || ctx.Err() != nil {
+		return err
+	}
+	slog.WarnContext(ctx, "Read replica failed, reading from the primary", "error", err)
+	replica.healthy.Store(false)
+	return unwrapAbortedRead(fn(primary))
+}
+
+// unwrapAbortedRead returns the error an abortedRead holds, or err itself.
+func unwrapAbortedRead(err error) error {
+	var aborted *abortedRead
+	if errors.As(err, &aborted) {
+		return aborted.err
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/db"
+)
+
+// openNamed returns a SQLite database that answers whoami with name, or
+// fails the query when name is empty.
+func openNamed(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	database, err := db.ConnectDB(&config.Config{
+		Storage:    config.StorageSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "blog.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if name != "" {
+		if _, err := database.Exec(`CREATE TABLE whoami (name TEXT); INSERT INTO whoami VALUES ($1)`, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return database
+}
+
+// whoami reads through replicas n times and returns who answered.
+func whoami(t *testing.T, ctx context.Context, replicas *Replicas, primary *sql.DB, n int) string {
+	t.Helper()
+	var answers []string
+	for range n {
+		var name string
+		err := replicas.read(ctx, primary, func(db dbtx) error {
+			return db.QueryRowContext(ctx, `SELECT name FROM whoami`).Scan(&name)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		answers = append(answers, name)
+	}
+	return strings.Join(answers, " ")
+}
+
+func TestReplicas(t *testing.T) {
+	ctx := context.Background()
+	primary := openNamed(t, "primary")
+
+	t.Run("without replicas", func(t *testing.T) {
+		if got := whoami(t, ctx, nil, primary, 2); got != "primary primary" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("round-robin over healthy replicas", func(t *testing.T) {
+		replicas := NewReplicas(openNamed(t, "a"), openNamed(t, "b"))
+		if got := whoami(t, ctx, replicas, primary, 1); got != "primary" {
+			t.Errorf("read from a replica before checking it: %q", got)
+		}
+		if healthy := replicas.Check(ctx); healthy != 2 {
+			t.Fatalf("%d replicas are healthy, want 2", healthy)
+		}
+		if got := whoami(t, ctx, replicas, primary, 4); got != "b a b a" {
+			t.Errorf("got %q", got)
+		}
+		if got := whoami(t, WithPrimaryReads(ctx), replicas, primary, 2); got != "primary primary" {
+			t.Errorf("read your writes: got %q", got)
+		}
+	})
+
+	t.Run("skips replicas that are down", func(t *testing.T) {
+		down := openNamed(t, "a")
+		replicas := NewReplicas(down, openNamed(t, "b"))
+		replicas.Check(ctx)
+		down.Close()
+		if healthy := replicas.Check(ctx); healthy != 1 {
+			t.Fatalf("%d replicas are healthy, want 1", healthy)
+		}
+		if got := whoami(t, ctx, replicas, primary, 3); got != "b b b" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("falls back to the primary when a read fails", func(t *testing.T) {
+		replicas := NewReplicas(openNamed(t, ""))
+		replicas.Check(ctx)
+		if got := whoami(t, ctx, replicas, primary, 2); got != "primary primary" {
+			t.Errorf("got %q", got)
+		}
+		if replicas.pick() != nil {
+			t.Error("the failing replica is still used")
+		}
+	})
+
+	t.Run("does not fall back when no post is found", func(t *testing.T) {
+		replicas := NewReplicas(openNamed(t, "a"))
+		replicas.Check(ctx)
+		calls := 0
+		err := replicas.read(ctx, primary, func(db dbtx) error {
+			calls++
+			return ErrorBlogPostNotFound
+		})
+		if err != ErrorBlogPostNotFound || calls != 1 || replicas.pick() == nil {
+			t.Errorf("read returned %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("does not fall back when the read is aborted", func(t *testing.T) {
+		replicas := NewReplicas(openNamed(t, "a"))
+		replicas.Check(ctx)
+		calls := 0
+		errWrite := errors.New("client went away")
+		err := replicas.read(ctx, primary, func(db dbtx) error {
+			calls++
+			return &abortedRead{err: errWrite}
+		})
+		if err != errWrite || calls != 1 || replicas.pick() == nil {
+			t.Errorf("read returned %v after %d calls", err, calls)
+		}
+	})
+}
@@ -0,0 +1,291 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// sqliteTimeLayout is how timestamps are stored in SQLite: UTC and fixed
+// width, so ordering the text column orders by time.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+type sqliteBlogPostDomain struct {
+	db dbtx
+}
+
+// NewSQLiteBlogPostDomain returns a BlogPostDomain backed by SQLite.
+func NewSQLiteBlogPostDomain(db *sql.DB) BlogPostDomain {
+	return &sqliteBlogPostDomain{db: db}
+}
+
+const sqliteBlogPostColumns = `id, title, COALESCE(description, ''), COALESCE(body, ''), COALESCE(slug, ''), tags, draft, created_at, updated_at`
+
+func (d *sqliteBlogPostDomain) CreateBlogPost(ctx context.Context, blog *models.BlogPost) (_ *uuid.UUID, err error) {
+	query := `
+       INSERT INTO blog_posts (id, title, description, body, slug, tags, draft, created_at, updated_at)
+       VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, "CreateBlogPost", query)
+	defer endStatement(span, &err)
+
+	ID := uuid.New()
+	if blog.ID != nil {
+		ID = *blog.ID
+	}
+
+	now := time.Now().UTC()
+	createdAt := timestampOr(blog.CreatedAt, now).Format(sqliteTimeLayout)
+	updatedAt := timestampOr(blog.UpdatedAt, now).Format(sqliteTimeLayout)
+	tags, err := json.Marshal(tagsOrEmpty(blog.Tags))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrorCreateBlogPostFailed, err)
+	}
+
+	_, err = d.db.ExecContext(ctx, query, ID.String(), blog.Title, blog.Description, blog.Body, blog.Slug, string(tags), blog.Draft, createdAt, updatedAt)
+	if err != nil {
+		return nil, writeError(ctx, ErrorCreateBlogPostFailed, err)
+	}
+	return &ID, nil
+}
+
+func (d *sqliteBlogPostDomain) GetBlogPost(ctx context.Context, ID *uuid.UUID) (*models.BlogPost, error) {
+	if ID == nil {
+		return nil, ErrorBlogPostNotFound
+	}
+	query := `
+       SELECT ` + sqliteBlogPostColumns + `
+       FROM blog_posts
+       WHERE id = $1
+    `
+	return d.getBlogPost(ctx, "GetBlogPost", query, ID.String())
+}
+
+func (d *sqliteBlogPostDomain) GetBlogPostBySlug(ctx context.Context, slug string) (*models.BlogPost, error) {
+	query := `
+       SELECT ` + sqliteBlogPostColumns + `
+       FROM blog_posts
+       WHERE slug = $1
+    `
+	return d.getBlogPost(ctx, "GetBlogPostBySlug", query, slug)
+}
+
+// getBlogPost runs query, the statement called name, for a single post.
+func (d *sqliteBlogPostDomain) getBlogPost(ctx context.Context, name, query string, arg any) (_ *models.BlogPost, err error) {
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, name, query)
+	defer endStatement(span, &err)
+
+	var blog models.BlogPost
+	err = scanSQLiteBlogPost(d.db.QueryRowContext(ctx, query, arg), &blog)
+	if err == sql.ErrNoRows {
+		return nil, ErrorBlogPostNotFound
+	} else if err != nil {
+		return nil, dbError(ctx, ErrorGetBlogPostFailed, err)
+	}
+	return &blog, nil
+}
+
+func (d *sqliteBlogPostDomain) GetBlogPosts(ctx context.Context) (_ []models.BlogPost, err error) {
+	query := `
+       SELECT ` + sqliteBlogPostColumns + `
+       FROM blog_posts
+       ORDER BY created_at DESC, rowid DESC
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, "GetBlogPosts", query)
+	defer endStatement(span, &err)
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError(ctx, ErrorGetBlogPostsFailed, err)
+	}
+	defer rows.Close()
+
+	blogs := []models.BlogPost{}
+	for rows.Next() {
+		var blog models.BlogPost
+		if err := scanSQLiteBlogPost(rows, &blog); err != nil {
+			return nil, dbError(ctx, ErrorGetBlogPostsFailed, err)
+		}
+		blogs = append(blogs, blog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, ErrorGetBlogPostsFailed, err)
+	}
+	return blogs, nil
+}
+
+func (d *sqliteBlogPostDomain) StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) (err error) {
+	query := `
+       SELECT ` + sqliteBlogPostColumns + `
+       FROM blog_posts
+       ORDER BY created_at DESC, rowid DESC
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, "StreamBlogPosts", query)
+	defer endStatement(span, &err)
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return dbError(ctx, ErrorGetBlogPostsFailed, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blog models.BlogPost
+		if err := scanSQLiteBlogPost(rows, &blog); err != nil {
+			return dbError(ctx, ErrorGetBlogPostsFailed, err)
+		}
+		if err := fn(&blog); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return dbError(ctx, ErrorGetBlogPostsFailed, err)
+	}
+	return nil
+}
+
+func (d *sqliteBlogPostDomain) UpdateBlogPost(ctx context.Context, blog *models.BlogPost) (err error) {
+	if blog.ID == nil {
+		return ErrorBlogPostNotFound
+	}
+	query := `
+       UPDATE blog_posts
+       SET title = $1, description = $2, body = $3, slug = NULLIF($4, ''), tags = $5, draft = $6, updated_at = $7
+       WHERE id = $8
+       RETURNING created_at, updated_at
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, "UpdateBlogPost", query)
+	defer endStatement(span, &err)
+
+	tags, err := json.Marshal(tagsOrEmpty(blog.Tags))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrorUpdateBlogPostFailed, err)
+	}
+	now := time.Now().UTC().Format(sqliteTimeLayout)
+	var createdAt, updatedAt string
+	err = d.db.QueryRowContext(ctx, query, blog.Title, blog.Description, blog.Body, blog.Slug, string(tags), blog.Draft, now, blog.ID.String()).
+		Scan(&createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return ErrorBlogPostNotFound
+	} else if err != nil {
+		return writeError(ctx, ErrorUpdateBlogPostFailed, err)
+	}
+
+	if blog.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return fmt.Errorf("%w: %w", ErrorUpdateBlogPostFailed, err)
+	}
+	if blog.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return fmt.Errorf("%w: %w", ErrorUpdateBlogPostFailed, err)
+	}
+	blog.CreatedAt, blog.UpdatedAt = blog.CreatedAt.UTC(), blog.UpdatedAt.UTC()
+	return nil
+}
+
+func (d *sqliteBlogPostDomain) UpsertBlogPost(ctx context.Context, blog *models.BlogPost) (_ bool, err error) {
+	if blog.ID == nil {
+		return false, ErrorUpsertBlogPostFailed
+	}
+	tags, err := json.Marshal(tagsOrEmpty(blog.Tags))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrorUpsertBlogPostFailed, err)
+	}
+
+	// SQLite can't tell an inserted row from an updated one, so check first.
+	// Transactions begin immediately, so no other writer can get in between.
+	query := `
+       INSERT INTO blog_posts (id, title, description, body, slug, tags, draft, created_at, updated_at)
+       VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+       ON CONFLICT (id) DO UPDATE
+       SET title = excluded.title, description = excluded.description, body = excluded.body,
+           slug = excluded.slug, tags = excluded.tags, draft = excluded.draft,
+           created_at = CASE WHEN $10 THEN excluded.created_at ELSE blog_posts.created_at END,
+           updated_at = excluded.updated_at
+       RETURNING created_at, updated_at
+    `
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, "UpsertBlogPost", query)
+	defer endStatement(span, &err)
+
+	var exists bool
+	var createdAt, updatedAt string
+	err = runInTx(ctx, d.db, nil, ErrorUpsertBlogPostFailed, func(tx dbtx) error {
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM blog_posts WHERE id = $1)`, blog.ID.String()).Scan(&exists)
+		if err != nil {
+			return dbError(ctx, ErrorUpsertBlogPostFailed, err)
+		}
+
+		now := time.Now().UTC()
+		err = tx.QueryRowContext(ctx, query, blog.ID.String(), blog.Title, blog.Description, blog.Body, blog.Slug, string(tags), blog.Draft,
+			timestampOr(blog.CreatedAt, now).Format(sqliteTimeLayout), timestampOr(blog.UpdatedAt, now).Format(sqliteTimeLayout), !blog.CreatedAt.IsZero()).
+			Scan(&createdAt, &updatedAt)
+		if err != nil {
+			return writeError(ctx, ErrorUpsertBlogPostFailed, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if blog.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrorUpsertBlogPostFailed, err)
+	}
+	if blog.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrorUpsertBlogPostFailed, err)
+	}
+	blog.CreatedAt, blog.UpdatedAt = blog.CreatedAt.UTC(), blog.UpdatedAt.UTC()
+	return !exists, nil
+}
+
+func (d *sqliteBlogPostDomain) DeleteBlogPost(ctx context.Context, ID *uuid.UUID) (err error) {
+	if ID == nil {
+		return ErrorBlogPostNotFound
+	}
+	query := `DELETE FROM blog_posts WHERE id = $1`
+	ctx, span := traceStatement(ctx, semconv.DBSystemSqlite, "DeleteBlogPost", query)
+	defer endStatement(span, &err)
+
+	result, err := d.db.ExecContext(ctx, query, ID.String())
+	if err != nil {
+		return dbError(ctx, ErrorDeleteBlogPostFailed, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return dbError(ctx, ErrorDeleteBlogPostFailed, err)
+	}
+	if rowsAffected == 0 {
+		return ErrorBlogPostNotFound
+	}
+	return nil
+}
+
+func scanSQLiteBlogPost(row rowScanner, blog *models.BlogPost) error {
+	var ID, tags, createdAt, updatedAt string
+	err := row.Scan(&ID, &blog.Title, &blog.Description, &blog.Body, &blog.Slug,
+		&tags, &blog.Draft, &createdAt, &updatedAt)
+	if err != nil {
+		return err
+	}
+
+	parsedID, err := uuid.Parse(ID)
+	if err != nil {
+		return err
+	}
+	blog.ID = &parsedID
+	if err := json.Unmarshal([]byte(tags), &blog.Tags); err != nil {
+		return err
+	}
+	if blog.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return err
+	}
+	if blog.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return err
+	}
+	blog.CreatedAt, blog.UpdatedAt = blog.CreatedAt.UTC(), blog.UpdatedAt.UTC()
+	return nil
+}
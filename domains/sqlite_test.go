@@ -0,0 +1,60 @@
+package domains_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/db"
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/domains/domaintest"
+)
+
+// openSQLite returns a migrated SQLite database in a temporary directory.
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	cfg := &config.Config{
+		Storage:    config.StorageSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "blog.db"),
+	}
+	database, err := db.ConnectDB(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	migrator, err := db.NewMigrator(database, cfg.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func TestSQLiteBlogPostDomain(t *testing.T) {
+	domaintest.Run(t, func(t *testing.T) domains.BlogPostDomain {
+		return domains.NewSQLiteBlogPostDomain(openSQLite(t))
+	})
+}
+
+func TestSQLiteBlogPostDomainTracing(t *testing.T) {
+	domaintest.RunTracing(t, func(t *testing.T) domains.BlogPostDomain {
+		return domains.NewSQLiteBlogPostDomain(openSQLite(t))
+	})
+}
+
+func TestSQLiteIdempotencyKeyDomain(t *testing.T) {
+	domaintest.RunIdempotencyKeys(t, func(t *testing.T) domains.IdempotencyKeyDomain {
+		return domains.NewSQLiteIdempotencyKeyDomain(openSQLite(t))
+	})
+}
+
+func TestSQLiteTxRunner(t *testing.T) {
+	domaintest.RunTx(t, func(t *testing.T) domains.TxRunner {
+		return domains.NewSQLiteTxRunner(openSQLite(t))
+	})
+}
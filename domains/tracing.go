@@ -0,0 +1,41 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the spans of SQL statements.
+const tracerName = "github.com/DurgeshKr2242/blogassessment/domains"
+
+// traceStatement starts a client span for the SQL statement called name as a
+// child of the span in ctx. The span holds the query text with its
+// placeholders but never the arguments bound to them.
+func traceStatement(ctx context.Context, system attribute.KeyValue, name, query string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			system,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+		),
+	)
+}
+
+// endStatement ends the span of a statement once it returns. errp points at
+// its named error result; finding no row is not recorded as a failure.
+func endStatement(span trace.Span, errp *error) {
+	if err := *errp; err != nil && !errors.Is(err, ErrorBlogPostNotFound) && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, Message(err))
+	}
+	span.End()
+}
@@ -0,0 +1,187 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+var ErrorTransactionFailed = errors.New("failed to run transaction")
+
+// maxTxAttempts bounds how often a transaction is run when the database
+// keeps reporting serialization failures.
+const maxTxAttempts = 3
+
+// txRetryDelay is the pause before the second attempt of a transaction,
+// growing with each further one.
+const txRetryDelay = 10 * time.Millisecond
+
+// Repos are the domains a unit of work runs on, all bound to the same
+// transaction.
+type Repos struct {
+	BlogPosts       BlogPostDomain
+	IdempotencyKeys IdempotencyKeyDomain
+}
+
+// TxRunner runs units of work that span several domains atomically.
+type TxRunner interface {
+	// WithTx runs fn with Repos bound to one transaction, committed when fn
+	// returns nil and rolled back otherwise; fn's error is returned as is.
+	// When the database reports a serialization failure or a deadlock, the
+	// transaction is rolled back and fn runs again, so fn must not keep
+	// state across calls.
+	WithTx(ctx context.Context, fn func(tx Repos) error) error
+}
+
+// dbtx is what the SQL domains need of a database, so they run the same
+// queries on a *sql.DB or bound to a *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txRunner struct {
+	db *sql.DB
+}
+
+// NewTxRunner returns a TxRunner for Postgres. Its transactions are
+// serializable.
+func NewTxRunner(db *sql.DB) TxRunner {
+	return &txRunner{db: db}
+}
+
+func (r *txRunner) WithTx(ctx context.Context, fn func(tx Repos) error) error {
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	return runInTx(ctx, r.db, opts, ErrorTransactionFailed, func(tx dbtx) error {
+		return fn(Repos{
+			BlogPosts:       &blogPostDomain{db: tx},
+			IdempotencyKeys: &idempotencyKeyDomain{db: tx},
+		})
+	})
+}
+
+type sqliteTxRunner struct {
+	db *sql.DB
+}
+
+// NewSQLiteTxRunner returns a TxRunner for SQLite.
+func NewSQLiteTxRunner(db *sql.DB) TxRunner {
+	return &sqliteTxRunner{db: db}
+}
+
+func (r *sqliteTxRunner) WithTx(ctx context.Context, fn func(tx Repos) error) error {
+	return runInTx(ctx, r.db, nil, ErrorTransactionFailed, func(tx dbtx) error {
+		return fn(Repos{
+			BlogPosts:       &sqliteBlogPostDomain{db: tx},
+			IdempotencyKeys: &sqliteIdempotencyKeyDomain{db: tx},
+		})
+	})
+}
+
+type memoryTxRunner struct {
+	blogPosts       *memoryBlogPostDomain
+	idempotencyKeys *memoryIdempotencyKeyDomain
+}
+
+// NewMemoryTxRunner returns a TxRunner over the domains returned by
+// NewMemoryBlogPostDomain and NewMemoryIdempotencyKeyDomain, and panics when
+// given others. fn works on copies of both, kept when it succeeds; other
+// operations on them wait until it is done.
+func NewMemoryTxRunner(blogPosts BlogPostDomain, idempotencyKeys IdempotencyKeyDomain) TxRunner {
+	return &memoryTxRunner{
+		blogPosts:       blogPosts.(*memoryBlogPostDomain),
+		idempotencyKeys: idempotencyKeys.(*memoryIdempotencyKeyDomain),
+	}
+}
+
+func (r *memoryTxRunner) WithTx(ctx context.Context, fn func(tx Repos) error) error {
+	return retryTx(ctx, func() error {
+		if ctx.Err() != nil {
+			return dbError(ctx, ErrorTransactionFailed, ctx.Err())
+		}
+
+		r.blogPosts.mu.Lock()
+		defer r.blogPosts.mu.Unlock()
+		r.idempotencyKeys.mu.Lock()
+		defer r.idempotencyKeys.mu.Unlock()
+
+		posts, keys := r.blogPosts.snapshot(), r.idempotencyKeys.snapshot()
+		if err := fn(Repos{BlogPosts: posts, IdempotencyKeys: keys}); err != nil {
+			return err
+		}
+		r.blogPosts.posts, r.blogPosts.seq = posts.posts, posts.seq
+		r.idempotencyKeys.keys = keys.keys
+		return nil
+	})
+}
+
+// runInTx runs fn in a new transaction on db, committing it when fn returns
+// nil and rolling it back otherwise, and retries it on serialization
+// failures. When db is already a transaction, fn joins it and is not
+// retried, as only the outermost transaction can run again. Failing to
+// begin or commit returns sentinel.
+func runInTx(ctx context.Context, db dbtx, opts *sql.TxOptions, sentinel error, fn func(tx dbtx) error) error {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	return retryTx(ctx, func() error {
+		tx, err := conn.BeginTx(ctx, opts)
+		if err != nil {
+			return dbError(ctx, sentinel, err)
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return dbError(ctx, sentinel, err)
+		}
+		return nil
+	})
+}
+
+// retryTx calls attempt until it succeeds, fails other than with a
+// serialization failure, or maxTxAttempts is reached.
+func retryTx(ctx context.Context, attempt func() error) error {
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || i == maxTxAttempts || !IsSerializationFailure(err) {
+			return err
+		}
+		slog.WarnContext(ctx, "Retrying transaction after a serialization failure", "attempt", i, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(i) * txRetryDelay):
+		}
+	}
+}
+
+// IsSerializationFailure reports whether err, or an error it wraps, is the
+// database aborting a transaction in favour of a concurrent one, so running
+// it again may succeed: a Postgres serialization failure or deadlock, or a
+// busy or locked SQLite database.
+func IsSerializationFailure(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		switch state.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		// The primary result code is the low byte of an extended one.
+		switch coded.Code() & 0xff {
+		case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED
+			return true
+		}
+	}
+	return false
+}
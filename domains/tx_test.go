@@ -0,0 +1,40 @@
+package domains_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/lib/pq"
+)
+
+// sqliteError has the result code of a modernc.org/sqlite error.
+type sqliteError int
+
+func (e sqliteError) Error() string { return "sqlite error" }
+func (e sqliteError) Code() int     { return int(e) }
+
+func TestIsSerializationFailure(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"serialization failure": {&pq.Error{Code: "40001"}, true},
+		"deadlock":              {&pq.Error{Code: "40P01"}, true},
+		"wrapped":               {fmt.Errorf("update: %w", &pq.Error{Code: "40001"}), true},
+		"unique violation":      {&pq.Error{Code: "23505"}, false},
+		"sqlite busy":           {sqliteError(5), true},
+		"sqlite busy snapshot":  {sqliteError(517), true},
+		"sqlite constraint":     {sqliteError(19), false},
+		"other":                 {errors.New("boom"), false},
+		"nil":                   {nil, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := domains.IsSerializationFailure(tc.err); got != tc.want {
+				t.Errorf("IsSerializationFailure(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
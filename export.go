@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/DurgeshKr2242/blogassessment/transfer"
+)
+
+// postWriter is implemented by the export formats.
+type postWriter interface {
+	WritePost(post *models.BlogPost) error
+}
+
+// runExport implements `blogassessment export [-format markdown|ndjson] [-o file]`,
+// which writes every post as a tar.gz of Markdown files or as NDJSON.
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "markdown", "export format: markdown (tar.gz) or ndjson")
+	output := flags.String("o", "-", "output file, - for stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: blogassessment export [-format markdown|ndjson] [-o file]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 0 || (*format != "markdown" && *format != "ndjson") {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	domain, closeDB, err := openBlogPostDomain(cfg)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer closeDB()
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Printf("Could not create %s: %v", *output, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	exported, err := writeExport(context.Background(), out, *format, domain)
+	if err != nil {
+		log.Printf("Export failed: %v", err)
+		return 1
+	}
+	log.Printf("Exported %d blog posts", exported)
+	return 0
+}
+
+// writeExport writes every post of domain to out as it is read, and returns
+// how many there were.
+func writeExport(ctx context.Context, out io.Writer, format string, domain domains.BlogPostDomain) (int, error) {
+	header := transfer.NewHeader()
+
+	var writer postWriter
+	var closeWriter func() error
+	if format == "ndjson" {
+		w, err := transfer.NewNDJSONWriter(out, header)
+		if err != nil {
+			return 0, err
+		}
+		writer, closeWriter = w, w.Close
+	} else {
+		w, err := transfer.NewArchiveWriter(out, header)
+		if err != nil {
+			return 0, err
+		}
+		writer, closeWriter = w, w.Close
+	}
+
+	exported := 0
+	err := domain.StreamBlogPosts(ctx, func(blog *models.BlogPost) error {
+		exported++
+		return writer.WritePost(blog)
+	})
+	if err != nil {
+		return exported, err
+	}
+	return exported, closeWriter()
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// codeUnauthorized is the problem code of a request without valid
+// credentials.
+const codeUnauthorized = "unauthorized"
+
+// AdminAuth is middleware that lets through only the requests sending token
+// as a bearer token in the Authorization header, and answers the others with
+// 401. token must not be empty.
+func AdminAuth(token string) gin.HandlerFunc {
+	// Comparing hashes keeps the comparison constant-time whatever the
+	// length of the token sent.
+	want := sha256.Sum256([]byte(token))
+	return func(c *gin.Context) {
+		scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		got := sha256.Sum256([]byte(strings.TrimSpace(credentials)))
+		if !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			writeProblem(c, http.StatusUnauthorized, codeUnauthorized, "a valid admin token is required", nil)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	server.GET("/admin/export", AdminAuth("s3cret"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	unauthorized := domainProblem(http.StatusUnauthorized, "unauthorized",
+		"a valid admin token is required", "/admin/export")
+	cases := map[string]struct {
+		authorization string
+		status        int
+		response      gin.H
+	}{
+		"When the token is valid": {
+			authorization: "Bearer s3cret",
+			status:        http.StatusNoContent,
+		},
+		"When the scheme is in another case": {
+			authorization: "bearer s3cret",
+			status:        http.StatusNoContent,
+		},
+		"When no token is sent": {
+			status:   http.StatusUnauthorized,
+			response: unauthorized,
+		},
+		"When the token is wrong": {
+			authorization: "Bearer s3cre",
+			status:        http.StatusUnauthorized,
+			response:      unauthorized,
+		},
+		"When the scheme is not Bearer": {
+			authorization: "Basic s3cret",
+			status:        http.StatusUnauthorized,
+			response:      unauthorized,
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/admin/export", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			server.ServeHTTP(res, req)
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+			if tc.response == nil {
+				return
+			}
+			if res.Header().Get("WWW-Authenticate") == "" {
+				t.Error("handler returned no WWW-Authenticate")
+			}
+			var got gin.H
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.response) {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.response)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/helpers"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/DurgeshKr2242/blogassessment/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// codeBatchTooLarge is the problem code of a batch with too many operations.
+const codeBatchTooLarge = "batch_too_large"
+
+// WithMaxBatchOperations limits how many operations a batch request may hold.
+func WithMaxBatchOperations(n int) Option {
+	return func(h *BlogPostHandler) {
+		h.maxBatch = n
+	}
+}
+
+// BatchResult reports the outcome of one operation of a batch. Status is the
+// HTTP status the operation would have had as a request of its own; Code,
+// Detail and Errors are set when it failed.
+type BatchResult struct {
+	Index  int                     `json:"index"`
+	Op     string                  `json:"op"`
+	Status int                     `json:"status"`
+	ID     *uuid.UUID              `json:"id,omitempty"`
+	Code   string                  `json:"code,omitempty"`
+	Detail string                  `json:"detail,omitempty"`
+	Errors []validation.FieldError `json:"errors,omitempty"`
+}
+
+// batchItem is a validated BatchOperation.
+type batchItem struct {
+	op     string
+	ID     *uuid.UUID
+	create models.CreateBlogPostRequest
+	update models.UpdateBlogPostRequest
+}
+
+// batchOperationError is the error of the operation at index that aborted
+// an atomic batch.
+type batchOperationError struct {
+	index int
+	err   error
+}
+
+func (e *batchOperationError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.index, e.err)
+}
+
+func (e *batchOperationError) Unwrap() error { return e.err }
+
+// BatchBlogPosts runs the create, update and delete operations of a
+// BatchRequest. In atomic mode they run in one transaction: any invalid
+// operation rejects the batch with every field error, and the first one
+// that fails rolls back those before it. In best-effort mode each runs on
+// its own and the response reports them one by one.
+func (h *BlogPostHandler) BatchBlogPosts(c *gin.Context) {
+	var req models.BatchRequest
+	if err := h.bindJSON(c, &req); err != nil {
+		writeValidationProblem(c, err)
+		return
+	}
+	if len(req.Operations) > h.maxBatch {
+		writeProblem(c, http.StatusRequestEntityTooLarge, codeBatchTooLarge,
+			fmt.Sprintf("a batch holds at most %d operations", h.maxBatch), nil)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = models.BatchAtomic
+	}
+
+	languages := validation.AcceptLanguage(c.GetHeader("Accept-Language"))
+	items := make([]batchItem, len(req.Operations))
+	results := make([]BatchResult, len(req.Operations))
+	var invalid []validation.FieldError
+	for i, op := range req.Operations {
+		results[i] = BatchResult{Index: i, Op: op.Op}
+		item, err := h.bindBatchOperation(op)
+		if err != nil {
+			errs := validation.AtIndex(validation.CustomValidationError(err, languages...), i)
+			invalid = append(invalid, errs...)
+			results[i].Status = http.StatusBadRequest
+			results[i].Code = codeValidationFailed
+			results[i].Errors = errs
+			continue
+		}
+		items[i] = item
+	}
+
+	ctx, cancel := operationContext(c, 0)
+	defer cancel()
+
+	if req.Mode == models.BatchBestEffort {
+		for i, item := range items {
+			if results[i].Status != 0 {
+				continue
+			}
+			results[i], _ = h.runBatchOperation(ctx, h.domain, i, item)
+		}
+		c.JSON(http.StatusOK, gin.H{"mode": req.Mode, "results": results})
+		return
+	}
+
+	if len(invalid) > 0 {
+		writeProblem(c, http.StatusBadRequest, codeValidationFailed, "the request is invalid", invalid)
+		return
+	}
+	err := h.withTx(ctx, func(tx domains.Repos) error {
+		for i, item := range items {
+			var err error
+			if results[i], err = h.runBatchOperation(ctx, tx.BlogPosts, i, item); err != nil {
+				return &batchOperationError{index: i, err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var opErr *batchOperationError
+		if errors.As(err, &opErr) {
+			writeDomainProblemDetail(c, err, fmt.Sprintf("operation %d: %s", opErr.index, domains.Message(opErr.err)))
+			return
+		}
+		writeDomainProblem(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"mode": req.Mode, "results": results})
+}
+
+// bindBatchOperation validates op and strictly decodes its post.
+func (h *BlogPostHandler) bindBatchOperation(op models.BatchOperation) (batchItem, error) {
+	if err := h.validator.Struct(op); err != nil {
+		return batchItem{}, err
+	}
+
+	item := batchItem{op: op.Op}
+	if op.ID != "" {
+		item.ID = helpers.ParseUUID(op.ID)
+	}
+	var post any
+	switch op.Op {
+	case models.BatchCreate:
+		post = &item.create
+	case models.BatchUpdate:
+		post = &item.update
+	default:
+		return item, nil
+	}
+	if err := validation.DecodeStrict(op.Post, post); err != nil {
+		return batchItem{}, err
+	}
+	if err := h.validator.Struct(post); err != nil {
+		return batchItem{}, err
+	}
+	return item, nil
+}
+
+// runBatchOperation runs item on domain, each domain call bounded by the
+// timeout of its operation, and reports how it went along with the error
+// of the domain, if any.
+func (h *BlogPostHandler) runBatchOperation(ctx context.Context, domain domains.BlogPostDomain, index int, item batchItem) (BatchResult, error) {
+	result := BatchResult{Index: index, Op: item.op, Status: http.StatusOK, ID: item.ID}
+
+	var err error
+	switch item.op {
+	case models.BatchCreate:
+		createCtx, cancel := timeoutContext(ctx, h.timeouts.Create)
+		defer cancel()
+		result.ID, err = domain.CreateBlogPost(createCtx, &models.BlogPost{
+			Title:       item.create.Title,
+			Description: item.create.Description,
+			Body:        item.create.Body,
+		})
+		result.Status = http.StatusCreated
+
+	case models.BatchUpdate:
+		getCtx, cancelGet := timeoutContext(ctx, h.timeouts.Get)
+		defer cancelGet()
+		var blog *models.BlogPost
+		if blog, err = domain.GetBlogPost(getCtx, item.ID); err != nil {
+			break
+		}
+		applyUpdate(item.update, blog)
+		updateCtx, cancelUpdate := timeoutContext(ctx, h.timeouts.Update)
+		defer cancelUpdate()
+		err = domain.UpdateBlogPost(updateCtx, blog)
+
+	case models.BatchDelete:
+		deleteCtx, cancel := timeoutContext(ctx, h.timeouts.Delete)
+		defer cancel()
+		err = domain.DeleteBlogPost(deleteCtx, item.ID)
+	}
+
+	if err != nil {
+		result.Status = domainErrorStatus(err)
+		result.Code = domains.ErrorCode(err)
+		result.Detail = domains.Message(err)
+		slog.DebugContext(ctx, "Batch operation failed", "index", index, "op", item.op, "error", err)
+	}
+	return result, err
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/gin-gonic/gin"
+)
+
+// TestBlogPostHandler_BatchBlogPosts runs every case against a fresh
+// in-memory domain holding mock.MockBlogPost, so rollbacks can be observed.
+func TestBlogPostHandler_BatchBlogPosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	create := `{"op": "create", "post": {"title": "Batch Title", "description": "Batch description", "body": "Batch body"}}`
+	update := `{"op": "update", "id": "` + mock.MockID.String() + `", "post": {"title": "Updated Title", "slug": "updated-title", "tags": ["batch"]}}`
+	deleteMissing := `{"op": "delete", "id": "0b1c6bb8-6b45-4c36-9a4d-6a0c4e4a2a2e"}`
+	invalid := `{"op": "create", "post": {"title": "Batch Title"}}`
+	batch := func(mode string, ops ...string) string {
+		return `{"mode": "` + mode + `", "operations": [` + strings.Join(ops, ", ") + `]}`
+	}
+
+	cases := map[string]struct {
+		body    string
+		status  int
+		results []string
+		problem gin.H
+		posts   int
+		title   string
+		slug    string
+		tags    []string
+	}{
+		"When an atomic batch succeeds": {
+			body:    batch("atomic", create, update),
+			status:  http.StatusOK,
+			results: []string{"0 create 201 ", "1 update 200 "},
+			posts:   2,
+			title:   "Updated Title",
+			slug:    "updated-title",
+			tags:    []string{"batch"},
+		},
+		"When the mode is left out": {
+			body:    `{"operations": [` + create + `]}`,
+			status:  http.StatusOK,
+			results: []string{"0 create 201 "},
+			posts:   2,
+			title:   mock.MockBlogPost.Title,
+		},
+		"When an operation of an atomic batch fails": {
+			body:   batch("atomic", create, update, deleteMissing),
+			status: http.StatusNotFound,
+			problem: domainProblem(http.StatusNotFound, "blog_post_not_found",
+				"operation 2: blog post not found", "/blog-post/batch"),
+			posts: 1,
+			title: mock.MockBlogPost.Title,
+		},
+		"When operations of an atomic batch are invalid": {
+			body:   batch("atomic", invalid, update, `{"op": "frob", "id": "`+mock.MockID.String()+`", "post": {}}`),
+			status: http.StatusBadRequest,
+			problem: validationProblem("/blog-post/batch",
+				gin.H{"index": 0, "field": "description", "code": "required", "message": "is required"},
+				gin.H{"index": 0, "field": "body", "code": "required", "message": "is required"},
+				gin.H{"index": 2, "field": "op", "code": "oneof", "message": "must be one of create update delete"},
+			),
+			posts: 1,
+			title: mock.MockBlogPost.Title,
+		},
+		"When a best-effort batch partly fails": {
+			body:    batch("best_effort", create, deleteMissing, invalid, update),
+			status:  http.StatusOK,
+			results: []string{"0 create 201 ", "1 delete 404 blog_post_not_found", "2 create 400 validation_failed", "3 update 200 "},
+			posts:   2,
+			title:   "Updated Title",
+			slug:    "updated-title",
+			tags:    []string{"batch"},
+		},
+		"When the mode is unknown": {
+			body:   batch("eventually", create),
+			status: http.StatusBadRequest,
+			problem: validationProblem("/blog-post/batch",
+				gin.H{"field": "mode", "code": "oneof", "message": "must be one of atomic best_effort"},
+			),
+			posts: 1,
+			title: mock.MockBlogPost.Title,
+		},
+		"When the batch is empty": {
+			body:   batch("atomic"),
+			status: http.StatusBadRequest,
+			problem: validationProblem("/blog-post/batch",
+				gin.H{"field": "operations", "code": "min", "message": "should hold at least 1 item(s)"},
+			),
+			posts: 1,
+			title: mock.MockBlogPost.Title,
+		},
+		"When the batch is too large": {
+			body:   batch("atomic", create, create, create, create, create),
+			status: http.StatusRequestEntityTooLarge,
+			problem: domainProblem(http.StatusRequestEntityTooLarge, "batch_too_large",
+				"a batch holds at most 4 operations", "/blog-post/batch"),
+			posts: 1,
+			title: mock.MockBlogPost.Title,
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			domain := domains.NewMemoryBlogPostDomain()
+			seed := mock.MockBlogPost
+			if _, err := domain.CreateBlogPost(context.Background(), &seed); err != nil {
+				t.Fatal(err)
+			}
+			server := gin.New()
+			runner := domains.NewMemoryTxRunner(domain, domains.NewMemoryIdempotencyKeyDomain())
+			handler := NewBlogPostHandler(domain, WithTxRunner(runner), WithMaxBatchOperations(4))
+			server.POST("/blog-post/batch", handler.BatchBlogPosts)
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/blog-post/batch", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			server.ServeHTTP(res, req)
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+
+			if tc.problem != nil {
+				var got gin.H
+				if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(tc.problem) {
+					t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.problem)
+				}
+			} else {
+				var got struct {
+					Results []BatchResult `json:"results"`
+				}
+				if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				var results []string
+				for _, r := range got.Results {
+					if r.ID == nil && r.Code == "" {
+						t.Errorf("result %d has no ID", r.Index)
+					}
+					results = append(results, fmt.Sprintf("%d %s %d %s", r.Index, r.Op, r.Status, r.Code))
+				}
+				if fmt.Sprint(results) != fmt.Sprint(tc.results) {
+					t.Errorf("handler returned unexpected results:\ngot  %q\nwant %q\n", results, tc.results)
+				}
+			}
+
+			posts, err := domain.GetBlogPosts(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(posts) != tc.posts {
+				t.Errorf("domain holds %d posts, want %d", len(posts), tc.posts)
+			}
+			var stored *models.BlogPost
+			if stored, err = domain.GetBlogPost(context.Background(), &mock.MockID); err != nil {
+				t.Fatal(err)
+			}
+			if stored.Title != tc.title || stored.Slug != tc.slug || fmt.Sprint(stored.Tags) != fmt.Sprint(tc.tags) {
+				t.Errorf("stored %q %q %v, want %q %q %v", stored.Title, stored.Slug, stored.Tags, tc.title, tc.slug, tc.tags)
+			}
+		})
+	}
+}
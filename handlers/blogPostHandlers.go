@@ -1,32 +1,189 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/DurgeshKr2242/blogassessment/config"
 	"github.com/DurgeshKr2242/blogassessment/domains"
 	"github.com/DurgeshKr2242/blogassessment/helpers"
 	"github.com/DurgeshKr2242/blogassessment/models"
 	"github.com/DurgeshKr2242/blogassessment/validation"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
 // BlogPostHandler handles blog post endpoints.
 type BlogPostHandler struct {
-	domain domains.BlogPostDomain
+	domain    domains.BlogPostDomain
+	timeouts  config.OperationTimeouts
+	rules     config.ValidationRules
+	validator *validator.Validate
+	maxBody   int64
+	maxBatch  int
+	txRunner  domains.TxRunner
+
+	idempotencyKeys domains.IdempotencyKeyDomain
+	idempotencyTTL  time.Duration
+}
+
+// Option configures a BlogPostHandler.
+type Option func(*BlogPostHandler)
+
+// WithTimeouts bounds every domain call by the timeout of its operation.
+func WithTimeouts(timeouts config.OperationTimeouts) Option {
+	return func(h *BlogPostHandler) {
+		h.timeouts = timeouts
+	}
+}
+
+// WithValidationRules replaces the default length limits of blog post fields.
+func WithValidationRules(rules config.ValidationRules) Option {
+	return func(h *BlogPostHandler) {
+		h.rules = rules
+	}
+}
+
+// WithMaxBodyBytes limits the size of request bodies. Larger ones are
+// rejected with 413.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *BlogPostHandler) {
+		h.maxBody = n
+	}
+}
+
+// WithTxRunner runs the units of work spanning several domain calls, such as
+// atomic batches, with runner. Without it they fail.
+func WithTxRunner(runner domains.TxRunner) Option {
+	return func(h *BlogPostHandler) {
+		h.txRunner = runner
+	}
 }
 
 // NewBlogPostHandler creates a new BlogPostHandler.
-func NewBlogPostHandler(domain domains.BlogPostDomain) *BlogPostHandler {
-	return &BlogPostHandler{domain: domain}
+func NewBlogPostHandler(domain domains.BlogPostDomain, opts ...Option) *BlogPostHandler {
+	h := &BlogPostHandler{
+		domain:   domain,
+		rules:    config.DefaultValidationRules(),
+		maxBody:  config.DefaultMaxBodyBytes,
+		maxBatch: config.DefaultMaxBatchOperations,
+
+		idempotencyTTL: config.DefaultIdempotencyTTL,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.validator = validation.NewValidator(h.rules)
+	return h
+}
+
+// withTx runs fn with the runner given to WithTxRunner.
+func (h *BlogPostHandler) withTx(ctx context.Context, fn func(tx domains.Repos) error) error {
+	if h.txRunner == nil {
+		return domains.ErrorTransactionFailed
+	}
+	return h.txRunner.WithTx(ctx, fn)
+}
+
+// readBody reads the request body, returning *http.MaxBytesError when it is
+// over the size limit.
+func (h *BlogPostHandler) readBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, io.EOF
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBody)
+	return io.ReadAll(c.Request.Body)
+}
+
+// bindJSON strictly decodes the request body into obj and validates it
+// against the binding tags and the configured rules.
+func (h *BlogPostHandler) bindJSON(c *gin.Context, obj any) error {
+	data, err := h.readBody(c)
+	if err != nil {
+		return err
+	}
+	if err := validation.DecodeStrict(data, obj); err != nil {
+		return err
+	}
+	return h.validator.Struct(obj)
+}
+
+// bindURI sets the fields of obj from the path parameters named in their uri
+// tags and validates it.
+func (h *BlogPostHandler) bindURI(c *gin.Context, obj any) error {
+	params := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = []string{p.Value}
+	}
+	if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+		return err
+	}
+	return h.validator.Struct(obj)
+}
+
+// bindQuery sets the fields of obj from the query parameters named in their
+// form tags and validates it.
+func (h *BlogPostHandler) bindQuery(c *gin.Context, obj any) error {
+	if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+		return err
+	}
+	return h.validator.Struct(obj)
+}
+
+// operationContext derives the context of a domain call from the request, so
+// the call stops when the client goes away or the timeout expires.
+func operationContext(c *gin.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return timeoutContext(c.Request.Context(), timeout)
+}
+
+// timeoutContext derives a context from parent that expires after timeout,
+// or only with parent when timeout is zero.
+func timeoutContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+type timeZoneQuery struct {
+	TZ string `form:"tz" binding:"omitempty,timezone"`
+}
+
+// bindTimeZone reads the optional tz query parameter, an IANA time zone name
+// such as "Europe/Berlin". Timestamps are rendered in UTC without it.
+func (h *BlogPostHandler) bindTimeZone(c *gin.Context) (*time.Location, error) {
+	var query timeZoneQuery
+	if err := h.bindQuery(c, &query); err != nil {
+		return nil, err
+	}
+	if query.TZ == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(query.TZ)
+}
+
+// domainErrorStatus maps an error returned by the domain to an HTTP status.
+func domainErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domains.ErrorBlogPostNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domains.ErrorSlugTaken):
+		return http.StatusConflict
+	case errors.Is(err, domains.ErrorTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
 }
 
 func (h *BlogPostHandler) CreateBlogPost(c *gin.Context) {
 	var req models.CreateBlogPostRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": validation.CustomValidationError(err),
-		})
+	if err := h.bindJSON(c, &req); err != nil {
+		writeValidationProblem(c, err)
 		return
 	}
 
@@ -36,15 +193,18 @@ func (h *BlogPostHandler) CreateBlogPost(c *gin.Context) {
 		Body:        req.Body,
 	}
 
-	blogID, err := h.domain.CreateBlogPost(&blog)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		return
-	}
+	ctx, cancel := operationContext(c, h.timeouts.Create)
+	defer cancel()
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "blog post create successfully",
-		"ID":      blogID,
+	h.respondOnce(c, ctx, func(tx domains.Repos) (int, any, error) {
+		blogID, err := tx.BlogPosts.CreateBlogPost(ctx, &blog)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusCreated, gin.H{
+			"message": "blog post create successfully",
+			"ID":      blogID,
+		}, nil
 	})
 }
 
@@ -53,37 +213,54 @@ func (h *BlogPostHandler) GetBlogPost(c *gin.Context) {
 		ID string `uri:"ID" binding:"required,uuid"`
 	}{}
 
-	if err := c.ShouldBindUri(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": validation.CustomValidationError(err),
-		})
+	if err := h.bindURI(c, &request); err != nil {
+		writeValidationProblem(c, err)
 		return
 	}
 
 	blogID := helpers.ParseUUID(request.ID)
 
-	blog, err := h.domain.GetBlogPost(blogID)
+	loc, err := h.bindTimeZone(c)
 	if err != nil {
-		if errors.Is(domains.ErrorBlogPostNotFound, err) {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		writeValidationProblem(c, err)
 		return
 	}
 
+	ctx, cancel := operationContext(c, h.timeouts.Get)
+	defer cancel()
+
+	blog, err := h.domain.GetBlogPost(ctx, blogID)
+	if err != nil {
+		writeDomainProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(blog))
 	c.JSON(http.StatusOK, gin.H{
-		"blog": blog,
+		"blog": blog.In(loc),
 	})
 }
 
 func (h *BlogPostHandler) GetBlogPosts(c *gin.Context) {
-	blogs, err := h.domain.GetBlogPosts()
+	loc, err := h.bindTimeZone(c)
+	if err != nil {
+		writeValidationProblem(c, err)
+		return
+	}
+
+	ctx, cancel := operationContext(c, h.timeouts.List)
+	defer cancel()
+
+	blogs, err := h.domain.GetBlogPosts(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		writeDomainProblem(c, err)
 		return
 	}
 
+	for i := range blogs {
+		blogs[i] = blogs[i].In(loc)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"blogs": blogs,
 	})
@@ -93,66 +270,160 @@ func (h *BlogPostHandler) UpdateBlogPost(c *gin.Context) {
 	request := struct {
 		ID string `uri:"ID" binding:"required,uuid"`
 	}{}
-	if err := c.ShouldBindUri(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": validation.CustomValidationError(err),
-		})
+	if err := h.bindURI(c, &request); err != nil {
+		writeValidationProblem(c, err)
 		return
 	}
 	blogID := helpers.ParseUUID(request.ID)
 
-	blog, err := h.domain.GetBlogPost(blogID)
+	loc, err := h.bindTimeZone(c)
 	if err != nil {
-		if errors.Is(domains.ErrorBlogPostNotFound, err) {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		writeValidationProblem(c, err)
 		return
 	}
 
-	var req models.UpdateBlogPostRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": validation.CustomValidationError(err)})
+	contentType := c.ContentType()
+	switch contentType {
+	case binding.MIMEJSON, "", mergePatchContentType, jsonPatchContentType:
+	default:
+		writeProblem(c, http.StatusUnsupportedMediaType, codeUnsupportedMediaType,
+			fmt.Sprintf("PATCH accepts %s, %s and %s", binding.MIMEJSON, mergePatchContentType, jsonPatchContentType), nil)
 		return
 	}
 
-	if req.Title != nil {
-		blog.Title = *req.Title
+	getCtx, cancelGet := operationContext(c, h.timeouts.Get)
+	defer cancelGet()
+
+	blog, err := h.domain.GetBlogPost(getCtx, blogID)
+	if err != nil {
+		writeDomainProblem(c, err)
+		return
 	}
-	if req.Description != nil {
-		blog.Description = *req.Description
+
+	switch contentType {
+	case mergePatchContentType, jsonPatchContentType:
+		err = h.applyPatch(c, contentType, blog)
+	default:
+		err = h.applyUpdateRequest(c, blog)
 	}
-	if req.Body != nil {
-		blog.Body = *req.Body
+	if err != nil {
+		writeValidationProblem(c, err)
+		return
 	}
 
-	if err := h.domain.UpdateBlogPost(blog); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	updateCtx, cancelUpdate := operationContext(c, h.timeouts.Update)
+	defer cancelUpdate()
+
+	if err := h.domain.UpdateBlogPost(updateCtx, blog); err != nil {
+		writeDomainProblem(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"blog": blog})
+	c.Header("ETag", etag(blog))
+	c.JSON(http.StatusOK, gin.H{"blog": blog.In(loc)})
+}
+
+// ReplaceBlogPost fully replaces the post with the ID in the path, or
+// creates it with that ID. If-Match makes the request apply only to the
+// current version of an existing post and If-None-Match: * only to a post
+// that doesn't exist yet. The precondition is checked in the transaction of
+// the upsert, so no concurrent writer can slip in between.
+func (h *BlogPostHandler) ReplaceBlogPost(c *gin.Context) {
+	request := struct {
+		ID string `uri:"ID" binding:"required,uuid"`
+	}{}
+	if err := h.bindURI(c, &request); err != nil {
+		writeValidationProblem(c, err)
+		return
+	}
+	blogID := helpers.ParseUUID(request.ID)
+
+	loc, err := h.bindTimeZone(c)
+	if err != nil {
+		writeValidationProblem(c, err)
+		return
+	}
+
+	blog := models.BlogPost{ID: blogID}
+	data, err := h.readBody(c)
+	if err == nil {
+		err = h.applyEditable(data, &blog)
+	}
+	if err != nil {
+		writeValidationProblem(c, err)
+		return
+	}
+
+	ctx, cancel := operationContext(c, 0)
+	defer cancel()
+
+	ifMatch, ifNoneMatch := c.GetHeader("If-Match"), c.GetHeader("If-None-Match")
+	var created bool
+	replace := func(tx domains.Repos) error {
+		if ifMatch != "" || ifNoneMatch != "" {
+			getCtx, cancelGet := timeoutContext(ctx, h.timeouts.Get)
+			defer cancelGet()
+
+			current, err := tx.BlogPosts.GetBlogPost(getCtx, blogID)
+			if err != nil && !errors.Is(err, domains.ErrorBlogPostNotFound) {
+				return err
+			}
+			exists := err == nil
+			if ifMatch != "" && (!exists || !etagMatches(ifMatch, etag(current))) ||
+				ifNoneMatch != "" && exists && etagMatches(ifNoneMatch, etag(current)) {
+				return errPreconditionFailed
+			}
+		}
+
+		upsertCtx, cancelUpsert := timeoutContext(ctx, h.timeouts.Update)
+		defer cancelUpsert()
+		// Upsert a copy, so a retried transaction does not pass the stored
+		// timestamps of the failed attempt as explicit ones.
+		upserted := blog
+		isCreate, err := tx.BlogPosts.UpsertBlogPost(upsertCtx, &upserted)
+		if err == nil {
+			blog, created = upserted, isCreate
+		}
+		return err
+	}
+	if ifMatch == "" && ifNoneMatch == "" {
+		err = replace(domains.Repos{BlogPosts: h.domain})
+	} else {
+		err = h.withTx(ctx, replace)
+	}
+	if errors.Is(err, errPreconditionFailed) {
+		writeProblem(c, http.StatusPreconditionFailed, codePreconditionFailed,
+			"the blog post does not match the request preconditions", nil)
+		return
+	} else if err != nil {
+		writeDomainProblem(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		c.Header("Location", c.Request.URL.Path)
+	}
+	c.Header("ETag", etag(&blog))
+	c.JSON(status, gin.H{"blog": blog.In(loc)})
 }
 
 func (h *BlogPostHandler) DeleteBlogPost(c *gin.Context) {
 	request := struct {
 		ID string `uri:"ID" binding:"uuid,required"`
 	}{}
-	if err := c.ShouldBindUri(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": validation.CustomValidationError(err),
-		})
+	if err := h.bindURI(c, &request); err != nil {
+		writeValidationProblem(c, err)
 		return
 	}
 	blogID := helpers.ParseUUID(request.ID)
 
-	if err := h.domain.DeleteBlogPost(blogID); err != nil {
-		if errors.Is(domains.ErrorBlogPostNotFound, err) {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	ctx, cancel := operationContext(c, h.timeouts.Delete)
+	defer cancel()
+
+	if err := h.domain.DeleteBlogPost(ctx, blogID); err != nil {
+		writeDomainProblem(c, err)
 		return
 	}
 
@@ -2,19 +2,49 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/DurgeshKr2242/blogassessment/domains"
 	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/DurgeshKr2242/blogassessment/models"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// domainProblem is the problem+json body of an error returned by the domain.
+func domainProblem(status int, code, detail, instance string) gin.H {
+	return gin.H{
+		"type":     "urn:blogassessment:problem:" + code,
+		"title":    http.StatusText(status),
+		"status":   status,
+		"detail":   detail,
+		"instance": instance,
+		"code":     code,
+	}
+}
+
+// validationProblem is the problem+json body of a request that failed to bind.
+func validationProblem(instance string, errs ...gin.H) gin.H {
+	return gin.H{
+		"type":     "urn:blogassessment:problem:validation_failed",
+		"title":    http.StatusText(http.StatusBadRequest),
+		"status":   http.StatusBadRequest,
+		"detail":   "the request is invalid",
+		"instance": instance,
+		"code":     "validation_failed",
+		"errors":   errs,
+	}
+}
+
 func TestBlogPostHandler_GetBlogPost(t *testing.T) {
 	server := gin.New()
 	fakeDomain := &mock.FakeService{}
@@ -48,33 +78,54 @@ func TestBlogPostHandler_GetBlogPost(t *testing.T) {
 				},
 			},
 		},
-		"When blog post is not found": {
-			ID:     mock.MockID.String(),
-			err:    mock.DBNotFoundError,
-			status: http.StatusNotFound,
+		"When blog post is rendered in a requested time zone": {
+			ID:     mock.MockID.String() + "?tz=Asia/Kolkata",
+			err:    mock.OK,
+			status: http.StatusOK,
 			response: gin.H{
-				"message": domains.ErrorBlogPostNotFound.Error(),
+				"blog": gin.H{
+					"id":          &mock.MockID,
+					"body":        "Some body for the blog",
+					"description": "Some description for the blog",
+					"title":       "Some title for blog",
+					"created_at":  "2025-02-08T03:31:38.640214+05:30",
+					"updated_at":  "2025-02-08T03:31:38.640214+05:30",
+				},
 			},
 		},
+		"When time zone is invalid": {
+			ID:     mock.MockID.String() + "?tz=Mars/Olympus",
+			err:    mock.OK,
+			status: http.StatusBadRequest,
+			response: validationProblem("/blog-post/"+mock.MockID.String(),
+				gin.H{"field": "tz", "code": "timezone", "message": "must be a valid IANA time zone"},
+			),
+		},
+		"When blog post is not found": {
+			ID:       mock.MockID.String(),
+			err:      mock.DBNotFoundError,
+			status:   http.StatusNotFound,
+			response: domainProblem(http.StatusNotFound, "blog_post_not_found", domains.ErrorBlogPostNotFound.Error(), "/blog-post/"+mock.MockID.String()),
+		},
 		"When blog post get call fails due to unknown reason": {
-			ID:     mock.MockID.String(),
-			err:    mock.DBOperationError,
-			status: http.StatusInternalServerError,
-			response: gin.H{
-				"message": domains.ErrorGetBlogPostFailed.Error(),
-			},
+			ID:       mock.MockID.String(),
+			err:      mock.DBOperationError,
+			status:   http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "get_blog_post_failed", domains.ErrorGetBlogPostFailed.Error(), "/blog-post/"+mock.MockID.String()),
+		},
+		"When blog post get call times out": {
+			ID:       mock.MockID.String(),
+			err:      mock.DBTimeoutError,
+			status:   http.StatusGatewayTimeout,
+			response: domainProblem(http.StatusGatewayTimeout, "timeout", domains.ErrorTimeout.Error(), "/blog-post/"+mock.MockID.String()),
 		},
 		"When Blog ID is invalid": {
 			ID:     "invalid-id",
 			err:    mock.OK,
 			status: http.StatusBadRequest,
-			response: gin.H{
-				"message": []gin.H{
-					{
-						"ID": "must be a valid UUID",
-					},
-				},
-			},
+			response: validationProblem("/blog-post/invalid-id",
+				gin.H{"field": "ID", "code": "uuid", "message": "must be a valid UUID"},
+			),
 		},
 	}
 
@@ -107,6 +158,9 @@ func TestBlogPostHandler_GetBlogPost(t *testing.T) {
 			if status := res.StatusCode; status != v.status {
 				t.Errorf("handler returned wrong status code: \ngot %v\nwant %v\n", status, v.status)
 			}
+			if contentType := res.Header.Get("Content-Type"); v.status >= http.StatusBadRequest && contentType != ProblemContentType {
+				t.Errorf("handler returned wrong content type: \ngot %v\nwant %v\n", contentType, ProblemContentType)
+			}
 
 			if !reflect.DeepEqual(v.response, body) {
 				if v.status == http.StatusOK {
@@ -169,11 +223,14 @@ func TestBlogPostHandler_GetBlogPosts(t *testing.T) {
 			},
 		},
 		"When blog posts get call fails due to unknown reason": {
-			err:    mock.DBOperationError,
-			status: http.StatusInternalServerError,
-			response: gin.H{
-				"message": domains.ErrorGetBlogPostsFailed.Error(),
-			},
+			err:      mock.DBOperationError,
+			status:   http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "get_blog_posts_failed", domains.ErrorGetBlogPostsFailed.Error(), "/blog-post"),
+		},
+		"When blog posts get call times out": {
+			err:      mock.DBTimeoutError,
+			status:   http.StatusGatewayTimeout,
+			response: domainProblem(http.StatusGatewayTimeout, "timeout", domains.ErrorTimeout.Error(), "/blog-post"),
 		},
 	}
 
@@ -248,10 +305,11 @@ func TestBlogPostHandler_CreateBlogPost(t *testing.T) {
 	httpServer := httptest.NewServer(server)
 
 	cases := map[string]struct {
-		body     gin.H
-		Err      mock.ErrMock
-		status   int
-		response gin.H
+		body           gin.H
+		acceptLanguage string
+		Err            mock.ErrMock
+		status         int
+		response       gin.H
 	}{
 		"When blog post is created successfully": {
 			body: gin.H{
@@ -272,11 +330,9 @@ func TestBlogPostHandler_CreateBlogPost(t *testing.T) {
 				"description": "Created description",
 				"body":        "Created body",
 			},
-			Err:    mock.DBOperationError,
-			status: http.StatusInternalServerError,
-			response: gin.H{
-				"message": domains.ErrorCreateBlogPostFailed.Error(),
-			},
+			Err:      mock.DBOperationError,
+			status:   http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "create_blog_post_failed", domains.ErrorCreateBlogPostFailed.Error(), "/blog-post"),
 		},
 		"When req body is invalid": {
 			body: gin.H{
@@ -285,16 +341,36 @@ func TestBlogPostHandler_CreateBlogPost(t *testing.T) {
 			},
 			Err:    mock.DBOperationError,
 			status: http.StatusBadRequest,
-			response: gin.H{
-				"message": []gin.H{
-					{
-						"Title": "should at least have 5 characters",
-					},
-					{
-						"Description": "is required",
-					},
-				},
+			response: validationProblem("/blog-post",
+				gin.H{"field": "description", "code": "required", "message": "is required"},
+				gin.H{"field": "title", "code": "min", "message": "should at least have 5 characters"},
+			),
+		},
+		"When req body is invalid and German is preferred": {
+			body: gin.H{
+				"title": "Cre",
+				"body":  "Created body",
+			},
+			acceptLanguage: "fr-CH, de-AT;q=0.8, en;q=0.5",
+			Err:            mock.DBOperationError,
+			status:         http.StatusBadRequest,
+			response: validationProblem("/blog-post",
+				gin.H{"field": "description", "code": "required", "message": "ist erforderlich"},
+				gin.H{"field": "title", "code": "min", "message": "muss mindestens 5 Zeichen lang sein"},
+			),
+		},
+		"When req body is invalid and no locale matches": {
+			body: gin.H{
+				"title": "Cre",
+				"body":  "Created body",
 			},
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			Err:            mock.DBOperationError,
+			status:         http.StatusBadRequest,
+			response: validationProblem("/blog-post",
+				gin.H{"field": "description", "code": "required", "message": "is required"},
+				gin.H{"field": "title", "code": "min", "message": "should at least have 5 characters"},
+			),
 		},
 	}
 
@@ -316,6 +392,9 @@ func TestBlogPostHandler_CreateBlogPost(t *testing.T) {
 				t.Error("unexpected error:", err)
 			}
 			req.Header.Set("Content-Type", "application/json")
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
 
 			res, err := client.Do(req)
 			if err != nil {
@@ -343,6 +422,88 @@ func TestBlogPostHandler_CreateBlogPost(t *testing.T) {
 	}
 }
 
+// TestBlogPostHandler_CreateBlogPostStrictJSON tests that request bodies are
+// decoded strictly and bounded in size.
+func TestBlogPostHandler_CreateBlogPostStrictJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &mock.FakeService{}
+
+	handler := NewBlogPostHandler(fakeDomain, WithMaxBodyBytes(128))
+	server.POST("/blog-post", handler.CreateBlogPost)
+
+	cases := map[string]struct {
+		body     string
+		status   int
+		response gin.H
+	}{
+		"When a field is misspelled": {
+			body:   `{"titel": "Created Title", "description": "Created description", "body": "Created body"}`,
+			status: http.StatusBadRequest,
+			response: validationProblem("/blog-post",
+				gin.H{"field": "titel", "code": "unknown_field", "message": "is not a known field"},
+			),
+		},
+		"When a key is given twice": {
+			body:   `{"title": "Created Title", "description": "Created description", "body": "Created body", "Title": "Other"}`,
+			status: http.StatusBadRequest,
+			response: validationProblem("/blog-post",
+				gin.H{"field": "Title", "code": "duplicate_key", "message": "is given more than once"},
+			),
+		},
+		"When data follows the JSON value": {
+			body:   `{"title": "Created Title", "description": "Created description", "body": "Created body"} {}`,
+			status: http.StatusBadRequest,
+			response: validationProblem("/blog-post",
+				gin.H{"field": "", "code": "trailing_data", "message": "request body must hold a single JSON value"},
+			),
+		},
+		"When the body is larger than allowed": {
+			body:   `{"title": "Created Title", "description": "Created description", "body": "` + strings.Repeat("x", 128) + `"}`,
+			status: http.StatusRequestEntityTooLarge,
+			response: gin.H{
+				"type":     "urn:blogassessment:problem:body_too_large",
+				"title":    http.StatusText(http.StatusRequestEntityTooLarge),
+				"status":   http.StatusRequestEntityTooLarge,
+				"detail":   "the request body exceeds 128 bytes",
+				"instance": "/blog-post",
+				"code":     "body_too_large",
+			},
+		},
+		"When the body is strict JSON": {
+			body:   `{"title": "Created Title", "description": "Created description", "body": "Created body"}`,
+			status: http.StatusCreated,
+			response: gin.H{
+				"message": "blog post create successfully",
+				"ID":      mock.MockID,
+			},
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			fakeDomain.Err = mock.OK
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/blog-post", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			server.ServeHTTP(res, req)
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+
+			var got gin.H
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.response) {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.response)
+			}
+		})
+	}
+}
+
 // TestBlogPostHandler_UpdateBlogPost tests the UpdateBlogPost handler.
 func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 	server := gin.New()
@@ -383,6 +544,27 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 				},
 			},
 		},
+		"When the slug and tags are updated": {
+			id: mock.MockID.String(),
+			body: gin.H{
+				"slug": "updated-title",
+				"tags": []string{"go", "blog"},
+			},
+			Err:    mock.OK,
+			status: http.StatusOK,
+			response: gin.H{
+				"blog": gin.H{
+					"id":          &mock.MockID,
+					"title":       mock.MockBlogPost.Title,
+					"description": mock.MockBlogPost.Description,
+					"body":        mock.MockBlogPost.Body,
+					"slug":        "updated-title",
+					"tags":        []any{"go", "blog"},
+					"created_at":  "2025-02-07T22:01:38.640214Z",
+					"updated_at":  "2025-02-07T22:01:38.640214Z",
+				},
+			},
+		},
 		"When blog post is not found": {
 			id: mock.MockID.String(),
 			body: gin.H{
@@ -390,11 +572,9 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 				"description": "Updated description",
 				"body":        "Updated body",
 			},
-			Err:    mock.DBNotFoundError,
-			status: http.StatusNotFound,
-			response: gin.H{
-				"message": domains.ErrorBlogPostNotFound.Error(),
-			},
+			Err:      mock.DBNotFoundError,
+			status:   http.StatusNotFound,
+			response: domainProblem(http.StatusNotFound, "blog_post_not_found", domains.ErrorBlogPostNotFound.Error(), "/blog-post/"+mock.MockID.String()),
 		},
 		"When update blog post call fails due to unknown reason": {
 			id: mock.MockID.String(),
@@ -403,11 +583,9 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 				"description": "Updated description",
 				"body":        "Updated body",
 			},
-			Err:    mock.DBOperationErrorUpdateBlog,
-			status: http.StatusInternalServerError,
-			response: gin.H{
-				"message": domains.ErrorUpdateBlogPostFailed.Error(),
-			},
+			Err:      mock.DBOperationErrorUpdateBlog,
+			status:   http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "update_blog_post_failed", domains.ErrorUpdateBlogPostFailed.Error(), "/blog-post/"+mock.MockID.String()),
 		},
 		"When blog ID is invalid": {
 			id: "invalidID",
@@ -418,13 +596,9 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 			},
 			Err:    mock.OK,
 			status: http.StatusBadRequest,
-			response: gin.H{
-				"message": []gin.H{
-					{
-						"ID": "must be a valid UUID",
-					},
-				},
-			},
+			response: validationProblem("/blog-post/invalidID",
+				gin.H{"field": "ID", "code": "uuid", "message": "must be a valid UUID"},
+			),
 		},
 		"When get blog post fails": {
 			id: mock.MockID.String(),
@@ -433,11 +607,9 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 				"description": "Updated description",
 				"body":        "Updated body",
 			},
-			Err:    mock.DBOperationError,
-			status: http.StatusInternalServerError,
-			response: gin.H{
-				"message": domains.ErrorGetBlogPostFailed.Error(),
-			},
+			Err:      mock.DBOperationError,
+			status:   http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "get_blog_post_failed", domains.ErrorGetBlogPostFailed.Error(), "/blog-post/"+mock.MockID.String()),
 		},
 		"When request body is invalid": {
 			id: mock.MockID.String(),
@@ -446,13 +618,9 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 			},
 			Err:    mock.OK,
 			status: http.StatusBadRequest,
-			response: gin.H{
-				"message": []gin.H{
-					{
-						"Title": "should at least have 5 characters",
-					},
-				},
-			},
+			response: validationProblem("/blog-post/"+mock.MockID.String(),
+				gin.H{"field": "title", "code": "min", "message": "should at least have 5 characters"},
+			),
 		},
 	}
 
@@ -501,6 +669,466 @@ func TestBlogPostHandler_UpdateBlogPost(t *testing.T) {
 	}
 }
 
+// TestBlogPostHandler_PatchBlogPost tests merge patch and JSON patch bodies
+// of the UpdateBlogPost handler.
+func TestBlogPostHandler_PatchBlogPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &mock.FakeService{}
+
+	handler := NewBlogPostHandler(fakeDomain)
+	server.PATCH("/blog-post/:ID", handler.UpdateBlogPost)
+
+	instance := "/blog-post/" + mock.MockID.String()
+	patched := func(fields gin.H) gin.H {
+		blog := gin.H{
+			"id":          &mock.MockID,
+			"title":       "Some title for blog",
+			"description": "Some description for the blog",
+			"body":        "Some body for the blog",
+			"created_at":  "2025-02-07T22:01:38.640214Z",
+			"updated_at":  "2025-02-07T22:01:38.640214Z",
+		}
+		for k, v := range fields {
+			blog[k] = v
+		}
+		return gin.H{"blog": blog}
+	}
+
+	cases := map[string]struct {
+		contentType string
+		body        string
+		status      int
+		response    gin.H
+	}{
+		"When a merge patch sets fields": {
+			contentType: "application/merge-patch+json",
+			body:        `{"title": "Merged Title", "tags": ["go", "json"], "draft": true}`,
+			status:      http.StatusOK,
+			response:    patched(gin.H{"title": "Merged Title", "tags": []string{"go", "json"}, "draft": true}),
+		},
+		"When a merge patch removes a required field": {
+			contentType: "application/merge-patch+json",
+			body:        `{"description": null}`,
+			status:      http.StatusBadRequest,
+			response: validationProblem(instance,
+				gin.H{"field": "description", "code": "required", "message": "is required"},
+			),
+		},
+		"When a merge patch adds an unknown field": {
+			contentType: "application/merge-patch+json",
+			body:        `{"id": "0b1c6bb8-6b45-4c36-9a4d-6a0c4e4a2a2e"}`,
+			status:      http.StatusBadRequest,
+			response: validationProblem(instance,
+				gin.H{"field": "id", "code": "unknown_field", "message": "is not a known field"},
+			),
+		},
+		"When a JSON patch edits tags": {
+			contentType: "application/json-patch+json",
+			body: `[
+				{"op": "test", "path": "/title", "value": "Some title for blog"},
+				{"op": "add", "path": "/tags", "value": ["go"]},
+				{"op": "add", "path": "/tags/-", "value": "patch"},
+				{"op": "replace", "path": "/body", "value": "A patched body"}
+			]`,
+			status:   http.StatusOK,
+			response: patched(gin.H{"tags": []string{"go", "patch"}, "body": "A patched body"}),
+		},
+		"When a JSON patch breaks the rules": {
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "replace", "path": "/title", "value": "Shrt"}]`,
+			status:      http.StatusBadRequest,
+			response: validationProblem(instance,
+				gin.H{"field": "title", "code": "min", "message": "should at least have 5 characters"},
+			),
+		},
+		"When a JSON patch test fails": {
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/title", "value": "Another title"}]`,
+			status:      http.StatusConflict,
+			response: gin.H{
+				"type":     "urn:blogassessment:problem:patch_test_failed",
+				"title":    http.StatusText(http.StatusConflict),
+				"status":   http.StatusConflict,
+				"detail":   "operation 0 (test /title): test failed",
+				"instance": instance,
+				"code":     "patch_test_failed",
+			},
+		},
+		"When a JSON patch path does not exist": {
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "remove", "path": "/tags/0"}]`,
+			status:      http.StatusUnprocessableEntity,
+			response: gin.H{
+				"type":     "urn:blogassessment:problem:patch_path_not_found",
+				"title":    http.StatusText(http.StatusUnprocessableEntity),
+				"status":   http.StatusUnprocessableEntity,
+				"detail":   "operation 0 (remove /tags/0): path not found",
+				"instance": instance,
+				"code":     "patch_path_not_found",
+			},
+		},
+		"When the content type is not supported": {
+			contentType: "text/plain",
+			body:        `title=Plain`,
+			status:      http.StatusUnsupportedMediaType,
+			response: gin.H{
+				"type":     "urn:blogassessment:problem:unsupported_media_type",
+				"title":    http.StatusText(http.StatusUnsupportedMediaType),
+				"status":   http.StatusUnsupportedMediaType,
+				"detail":   "PATCH accepts application/json, application/merge-patch+json and application/json-patch+json",
+				"instance": instance,
+				"code":     "unsupported_media_type",
+			},
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			fakeDomain.Err = mock.OK
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, instance, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			server.ServeHTTP(res, req)
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+
+			var got gin.H
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.response) {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.response)
+			}
+		})
+	}
+}
+
+// TestBlogPostHandler_ReplaceBlogPost tests the ReplaceBlogPost handler.
+func TestBlogPostHandler_ReplaceBlogPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &mock.FakeService{}
+
+	handler := NewBlogPostHandler(fakeDomain, WithTxRunner(&mock.FakeTxRunner{Repos: domains.Repos{BlogPosts: fakeDomain}}))
+	server.PUT("/blog-post/:ID", handler.ReplaceBlogPost)
+
+	newID := uuid.MustParse("0b1c6bb8-6b45-4c36-9a4d-6a0c4e4a2a2e")
+	currentETag := etag(&mock.MockBlogPost)
+	body := `{"title": "Synced Title", "description": "Synced description", "body": "Synced body", "tags": ["cms"]}`
+	replaced := func(ID *uuid.UUID) gin.H {
+		return gin.H{
+			"blog": gin.H{
+				"id":          ID,
+				"title":       "Synced Title",
+				"description": "Synced description",
+				"body":        "Synced body",
+				"tags":        []string{"cms"},
+				"created_at":  "2025-02-07T22:01:38.640214Z",
+				"updated_at":  "2025-02-07T22:01:38.640214Z",
+			},
+		}
+	}
+	preconditionFailed := func(ID uuid.UUID) gin.H {
+		return domainProblem(http.StatusPreconditionFailed, "precondition_failed",
+			"the blog post does not match the request preconditions", "/blog-post/"+ID.String())
+	}
+
+	cases := map[string]struct {
+		ID       uuid.UUID
+		body     string
+		headers  map[string]string
+		err      mock.ErrMock
+		status   int
+		location string
+		response gin.H
+	}{
+		"When the post is created": {
+			ID:       newID,
+			body:     body,
+			status:   http.StatusCreated,
+			location: "/blog-post/" + newID.String(),
+			response: replaced(&newID),
+		},
+		"When the post is replaced": {
+			ID:       mock.MockID,
+			body:     body,
+			status:   http.StatusOK,
+			response: replaced(&mock.MockID),
+		},
+		"When If-None-Match is * and the post does not exist": {
+			ID:       newID,
+			body:     body,
+			headers:  map[string]string{"If-None-Match": "*"},
+			status:   http.StatusCreated,
+			location: "/blog-post/" + newID.String(),
+			response: replaced(&newID),
+		},
+		"When If-None-Match is * and the post exists": {
+			ID:       mock.MockID,
+			body:     body,
+			headers:  map[string]string{"If-None-Match": "*"},
+			status:   http.StatusPreconditionFailed,
+			response: preconditionFailed(mock.MockID),
+		},
+		"When If-Match holds the current ETag": {
+			ID:       mock.MockID,
+			body:     body,
+			headers:  map[string]string{"If-Match": `"stale", ` + currentETag},
+			status:   http.StatusOK,
+			response: replaced(&mock.MockID),
+		},
+		"When If-Match holds a stale ETag": {
+			ID:       mock.MockID,
+			body:     body,
+			headers:  map[string]string{"If-Match": `"stale"`},
+			status:   http.StatusPreconditionFailed,
+			response: preconditionFailed(mock.MockID),
+		},
+		"When If-Match is * and the post does not exist": {
+			ID:       newID,
+			body:     body,
+			headers:  map[string]string{"If-Match": "*"},
+			status:   http.StatusPreconditionFailed,
+			response: preconditionFailed(newID),
+		},
+		"When the body is not a full post": {
+			ID:     mock.MockID,
+			body:   `{"title": "Synced Title"}`,
+			status: http.StatusBadRequest,
+			response: validationProblem("/blog-post/"+mock.MockID.String(),
+				gin.H{"field": "description", "code": "required", "message": "is required"},
+				gin.H{"field": "body", "code": "required", "message": "is required"},
+			),
+		},
+		"When the upsert fails": {
+			ID:     mock.MockID,
+			body:   body,
+			err:    mock.DBOperationError,
+			status: http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "upsert_blog_post_failed",
+				domains.ErrorUpsertBlogPostFailed.Error(), "/blog-post/"+mock.MockID.String()),
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			fakeDomain.Err = tc.err
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/blog-post/"+tc.ID.String(), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			server.ServeHTTP(res, req)
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+			if location := res.Header().Get("Location"); location != tc.location {
+				t.Errorf("handler returned wrong Location:\ngot  %v\nwant %v\n", location, tc.location)
+			}
+			if res.Code < http.StatusBadRequest && res.Header().Get("ETag") == "" {
+				t.Error("handler returned no ETag")
+			}
+
+			var got gin.H
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.response) {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.response)
+			}
+		})
+	}
+}
+
+// TestBlogPostHandler_ReplaceBlogPostConcurrently sends concurrent
+// replacements of the same version, of which only one may apply.
+func TestBlogPostHandler_ReplaceBlogPostConcurrently(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	domain := domains.NewMemoryBlogPostDomain()
+	seed := mock.MockBlogPost
+	if _, err := domain.CreateBlogPost(context.Background(), &seed); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := domain.GetBlogPost(context.Background(), &mock.MockID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := domains.NewMemoryTxRunner(domain, domains.NewMemoryIdempotencyKeyDomain())
+	handler := NewBlogPostHandler(domain, WithTxRunner(runner))
+	server.PUT("/blog-post/:ID", handler.ReplaceBlogPost)
+
+	const writers = 8
+	statuses := make(chan int, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"title": "Title %d", "description": "Some description", "body": "Some longer body"}`, i)
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/blog-post/"+mock.MockID.String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", etag(stored))
+			server.ServeHTTP(res, req)
+			statuses <- res.Code
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	counts := map[int]int{}
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[http.StatusOK] != 1 || counts[http.StatusPreconditionFailed] != writers-1 {
+		t.Errorf("replacements returned %v, want one 200 and the rest 412", counts)
+	}
+}
+
+// TestBlogPostHandler_PatchETag checks that the ETag PATCH returns is the
+// one of the stored post, so it can be sent back in If-Match.
+func TestBlogPostHandler_PatchETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	domain := domains.NewMemoryBlogPostDomain()
+	seed := mock.MockBlogPost
+	if _, err := domain.CreateBlogPost(context.Background(), &seed); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := domains.NewMemoryTxRunner(domain, domains.NewMemoryIdempotencyKeyDomain())
+	handler := NewBlogPostHandler(domain, WithTxRunner(runner))
+	server.GET("/blog-post/:ID", handler.GetBlogPost)
+	server.PATCH("/blog-post/:ID", handler.UpdateBlogPost)
+	server.PUT("/blog-post/:ID", handler.ReplaceBlogPost)
+	send := func(method, body, ifMatch string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/blog-post/"+mock.MockID.String(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		server.ServeHTTP(res, req)
+		return res
+	}
+
+	patched := send(http.MethodPatch, `{"title": "Patched Title"}`, "")
+	if patched.Code != http.StatusOK {
+		t.Fatalf("PATCH returned %v: %s", patched.Code, patched.Body)
+	}
+	tag := patched.Header().Get("ETag")
+	if got := send(http.MethodGet, "", "").Header().Get("ETag"); got != tag {
+		t.Errorf("PATCH returned ETag %s, GET %s", tag, got)
+	}
+
+	replaced := send(http.MethodPut, `{"title": "Replaced Title", "description": "Some description", "body": "Some longer body"}`, tag)
+	if replaced.Code != http.StatusOK {
+		t.Errorf("PUT with the ETag of PATCH returned %v: %s", replaced.Code, replaced.Body)
+	}
+}
+
+// TestBlogPostHandler_Slugs tests the slugs PATCH and PUT accept, against
+// an in-memory domain where "taken" belongs to another post.
+func TestBlogPostHandler_Slugs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	instance := "/blog-post/" + mock.MockID.String()
+	replacement := func(slug string) string {
+		return `{"title": "Some Title", "description": "Some description", "body": "Some longer body", "slug": "` + slug + `"}`
+	}
+
+	cases := map[string]struct {
+		method   string
+		body     string
+		status   int
+		response gin.H
+	}{
+		"When PATCH sets a free slug": {
+			method: http.MethodPatch,
+			body:   `{"slug": "free-slug-2"}`,
+			status: http.StatusOK,
+		},
+		"When PATCH removes the slug": {
+			method: http.MethodPatch,
+			body:   `{"slug": ""}`,
+			status: http.StatusOK,
+		},
+		"When PATCH sets a taken slug": {
+			method:   http.MethodPatch,
+			body:     `{"slug": "taken"}`,
+			status:   http.StatusConflict,
+			response: domainProblem(http.StatusConflict, "slug_taken", domains.ErrorSlugTaken.Error(), instance),
+		},
+		"When PATCH sets a malformed slug": {
+			method: http.MethodPatch,
+			body:   `{"slug": "Not a slug"}`,
+			status: http.StatusBadRequest,
+			response: validationProblem(instance,
+				gin.H{"field": "slug", "code": "slug", "message": "must be lowercase letters and digits separated by single hyphens"},
+			),
+		},
+		"When PUT sets a taken slug": {
+			method:   http.MethodPut,
+			body:     replacement("taken"),
+			status:   http.StatusConflict,
+			response: domainProblem(http.StatusConflict, "slug_taken", domains.ErrorSlugTaken.Error(), instance),
+		},
+		"When PUT sets a malformed slug": {
+			method: http.MethodPut,
+			body:   replacement("trailing-"),
+			status: http.StatusBadRequest,
+			response: validationProblem(instance,
+				gin.H{"field": "slug", "code": "slug", "message": "must be lowercase letters and digits separated by single hyphens"},
+			),
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			domain := domains.NewMemoryBlogPostDomain()
+			seed := mock.MockBlogPost
+			seed.Slug = "mine"
+			other := models.BlogPost{Title: "Other Title", Description: "Other description", Body: "Other body", Slug: "taken"}
+			for _, post := range []*models.BlogPost{&seed, &other} {
+				if _, err := domain.CreateBlogPost(context.Background(), post); err != nil {
+					t.Fatal(err)
+				}
+			}
+			server := gin.New()
+			handler := NewBlogPostHandler(domain)
+			server.PATCH("/blog-post/:ID", handler.UpdateBlogPost)
+			server.PUT("/blog-post/:ID", handler.ReplaceBlogPost)
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, instance, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			server.ServeHTTP(res, req)
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+			if tc.response == nil {
+				return
+			}
+			var got gin.H
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.response) {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.response)
+			}
+		})
+	}
+}
+
 // TestBlogPostHandler_DeleteBlogPost tests the DeleteBlogPost handler.
 func TestBlogPostHandler_DeleteBlogPost(t *testing.T) {
 	server := gin.New()
@@ -528,32 +1156,24 @@ func TestBlogPostHandler_DeleteBlogPost(t *testing.T) {
 			},
 		},
 		"When blog post is not found": {
-			id:     mock.MockID.String(),
-			Err:    mock.DBNotFoundError,
-			status: http.StatusNotFound,
-			response: gin.H{
-				"message": domains.ErrorBlogPostNotFound.Error(),
-			},
+			id:       mock.MockID.String(),
+			Err:      mock.DBNotFoundError,
+			status:   http.StatusNotFound,
+			response: domainProblem(http.StatusNotFound, "blog_post_not_found", domains.ErrorBlogPostNotFound.Error(), "/blog-post/"+mock.MockID.String()),
 		},
 		"When delete blog post call fails due to unknown reason": {
-			id:     mock.MockID.String(),
-			Err:    mock.DBOperationError,
-			status: http.StatusInternalServerError,
-			response: gin.H{
-				"message": domains.ErrorDeleteBlogPostFailed.Error(),
-			},
+			id:       mock.MockID.String(),
+			Err:      mock.DBOperationError,
+			status:   http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "delete_blog_post_failed", domains.ErrorDeleteBlogPostFailed.Error(), "/blog-post/"+mock.MockID.String()),
 		},
 		"When blog ID is invalid": {
 			id:     "invalidID",
 			Err:    mock.OK,
 			status: http.StatusBadRequest,
-			response: gin.H{
-				"message": []gin.H{
-					{
-						"ID": "must be a valid UUID",
-					},
-				},
-			},
+			response: validationProblem("/blog-post/invalidID",
+				gin.H{"field": "ID", "code": "uuid", "message": "must be a valid UUID"},
+			),
 		},
 	}
 
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+// errPreconditionFailed aborts a transaction whose If-Match or If-None-Match
+// precondition does not hold.
+var errPreconditionFailed = errors.New("precondition failed")
+
+// etag returns a strong entity tag of the stored state of blog, which
+// changes whenever any of its fields does.
+func etag(blog *models.BlogPost) string {
+	data, err := json.Marshal(blog.In(time.UTC))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header value
+// lists tag or is "*". Weak tags never match, as If-Match compares strongly.
+func etagMatches(header, tag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/DurgeshKr2242/blogassessment/transfer"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportBlogPosts streams every blog post as NDJSON in the versioned export
+// format read by the import command, writing each post as it is read. The
+// response starts with the first post, so an error before it still gets a
+// problem response; one after it cuts the stream short before the trailer,
+// which the import detects. The export is only bounded by the write timeout
+// of the server, as the list timeout would cut large ones short.
+func (h *BlogPostHandler) ExportBlogPosts(c *gin.Context) {
+	ctx, cancel := operationContext(c, 0)
+	defer cancel()
+
+	var writer *transfer.NDJSONWriter
+	start := func() error {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="blog-export.ndjson"`)
+		c.Status(http.StatusOK)
+
+		var err error
+		writer, err = transfer.NewNDJSONWriter(c.Writer, transfer.NewHeader())
+		return err
+	}
+
+	started := false
+	err := h.domain.StreamBlogPosts(ctx, func(blog *models.BlogPost) error {
+		if !started {
+			started = true
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.WritePost(blog); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil && !started {
+		writeDomainProblem(c, err)
+		return
+	}
+	if err == nil && !started {
+		// Without posts, the export is just its header and trailer.
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		_ = c.Error(err)
+	}
+}
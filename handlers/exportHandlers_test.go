@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/DurgeshKr2242/blogassessment/transfer"
+	"github.com/gin-gonic/gin"
+)
+
+// TestBlogPostHandler_ExportBlogPosts tests the ExportBlogPosts handler.
+func TestBlogPostHandler_ExportBlogPosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &mock.FakeService{}
+
+	handler := NewBlogPostHandler(fakeDomain)
+	server.GET("/admin/export", handler.ExportBlogPosts)
+
+	cases := map[string]struct {
+		err      mock.ErrMock
+		status   int
+		titles   []string
+		response gin.H
+	}{
+		"When the posts are exported": {
+			status: http.StatusOK,
+			titles: []string{mock.MockBlogPost.Title},
+		},
+		"When the posts cannot be read": {
+			err:    mock.DBOperationError,
+			status: http.StatusInternalServerError,
+			response: domainProblem(http.StatusInternalServerError, "get_blog_posts_failed",
+				"failed to get blog posts", "/admin/export"),
+		},
+	}
+
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			fakeDomain.Err = tc.err
+
+			res := httptest.NewRecorder()
+			server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/admin/export", nil))
+
+			if res.Code != tc.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, tc.status)
+			}
+
+			if tc.response != nil {
+				var got gin.H
+				if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(tc.response) {
+					t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, tc.response)
+				}
+				return
+			}
+
+			if contentType := res.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+				t.Errorf("handler returned Content-Type %q", contentType)
+			}
+			items, err := transfer.ReadNDJSON(res.Body, "export")
+			if err != nil {
+				t.Fatal(err)
+			}
+			titles := []string{}
+			for _, item := range items {
+				titles = append(titles, item.Post.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tc.titles) {
+				t.Errorf("handler exported %v, want %v", titles, tc.titles)
+			}
+		})
+	}
+}
+
+// failingStream fails after streaming its first post.
+type failingStream struct {
+	*mock.FakeService
+}
+
+func (s failingStream) StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) error {
+	blog := mock.MockBlogPost
+	if err := fn(&blog); err != nil {
+		return err
+	}
+	return domains.ErrorGetBlogPostsFailed
+}
+
+// TestBlogPostHandler_ExportBlogPostsTruncated checks that an export failing
+// after the first post ends without the trailer, so imports reject it.
+func TestBlogPostHandler_ExportBlogPostsTruncated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	handler := NewBlogPostHandler(failingStream{&mock.FakeService{}})
+	server.GET("/admin/export", handler.ExportBlogPosts)
+
+	res := httptest.NewRecorder()
+	server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/admin/export", nil))
+
+	if res.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, http.StatusOK)
+	}
+	if _, err := transfer.ReadNDJSON(res.Body, "export"); !errors.Is(err, transfer.ErrorTruncatedExport) {
+		t.Errorf("reading the export failed with %v, want %v", err, transfer.ErrorTruncatedExport)
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCheckTimeout bounds each readiness check when no other timeout is
+// given to NewHealth.
+const DefaultCheckTimeout = 2 * time.Second
+
+// Statuses of the server and of the components it depends on.
+const (
+	StatusOK           = "OK"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting down"
+)
+
+// Check reports whether a component the server depends on works, returning
+// an error when it doesn't. It should give up once ctx is done.
+type Check func(ctx context.Context) error
+
+// ComponentStatus is the result of one readiness check.
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthStatus is the body of the health endpoints.
+type HealthStatus struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health answers the liveness and readiness probes of the server. The server
+// is ready while every registered check passes, and stops being so once
+// shutdown starts, so load balancers drain it before it goes away.
+type Health struct {
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewHealth returns a Health without checks, bounding each check it gets by
+// timeout.
+func NewHealth(timeout time.Duration) *Health {
+	return &Health{timeout: timeout}
+}
+
+// Register adds the readiness check of the component called name. Other
+// subsystems can add theirs at any time. It panics when name is already
+// registered, since the second check would hide the result of the first.
+func (h *Health) Register(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, registered := range h.checks {
+		if registered.name == name {
+			panic(fmt.Sprintf("health: check %q registered twice", name))
+		}
+	}
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// ShutDown makes the readiness check fail from now on.
+func (h *Health) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Live handles GET /livez: 200 as long as the process serves requests, even
+// while it shuts down, since restarting it would not help.
+func (h *Health) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthStatus{Status: StatusOK})
+}
+
+// Ready handles GET /readyz: 200 when every check passes, and 503 when one
+// fails or the server is shutting down. Checks run concurrently, and the
+// status and latency of each is reported.
+func (h *Health) Ready(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, HealthStatus{Status: StatusShuttingDown})
+		return
+	}
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	body := HealthStatus{Status: StatusOK}
+	if len(checks) > 0 {
+		body.Components = make(map[string]ComponentStatus, len(checks))
+	}
+	results := make([]ComponentStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(c.Request.Context(), check)
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	for i, check := range checks {
+		body.Components[check.name] = results[i]
+		if results[i].Status != StatusOK {
+			body.Status = StatusFailing
+			status = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(status, body)
+}
+
+// run runs check within the timeout and times it. The probes are not
+// authenticated, so the error of a failing check is logged rather than
+// reported.
+func (h *Health) run(parent context.Context, check namedCheck) ComponentStatus {
+	ctx, cancel := timeoutContext(parent, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.check(ctx)
+	result := ComponentStatus{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status, result.Error = StatusFailing, StatusFailing
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = "timed out"
+		}
+		slog.WarnContext(parent, "Readiness check failed", "component", check.name, "error", err)
+	}
+	return result
+}
+
+// Heartbeat tracks a background worker that beats every interval, each time
+// round its loop. Its Check fails once the worker has stopped or missed two
+// beats in a row.
+type Heartbeat struct {
+	interval time.Duration
+	last     atomic.Int64
+	stopped  atomic.Bool
+}
+
+// NewHeartbeat returns a Heartbeat for a worker beating every interval,
+// counting its start as the first beat.
+func NewHeartbeat(interval time.Duration) *Heartbeat {
+	b := &Heartbeat{interval: interval}
+	b.Beat()
+	return b
+}
+
+// Beat records that the worker is alive.
+func (b *Heartbeat) Beat() {
+	b.last.Store(time.Now().UnixNano())
+}
+
+// Stop records that the worker has returned.
+func (b *Heartbeat) Stop() {
+	b.stopped.Store(true)
+}
+
+// Check is the readiness check of the worker.
+func (b *Heartbeat) Check(ctx context.Context) error {
+	if b.stopped.Load() {
+		return errors.New("worker stopped")
+	}
+	if since := time.Since(time.Unix(0, b.last.Load())); since > 2*b.interval {
+		return fmt.Errorf("worker last beat %s ago", since.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	passing := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	cases := map[string]struct {
+		checks       map[string]Check
+		shuttingDown bool
+		wantStatus   int
+		want         HealthStatus
+		wantLog      string
+	}{
+		"When there are no checks": {
+			wantStatus: http.StatusOK,
+			want:       HealthStatus{Status: StatusOK},
+		},
+		"When every check passes": {
+			checks:     map[string]Check{"database": passing, "worker": passing},
+			wantStatus: http.StatusOK,
+			want: HealthStatus{Status: StatusOK, Components: map[string]ComponentStatus{
+				"database": {Status: StatusOK},
+				"worker":   {Status: StatusOK},
+			}},
+		},
+		"When a check fails": {
+			checks:     map[string]Check{"database": failing, "worker": passing},
+			wantStatus: http.StatusServiceUnavailable,
+			want: HealthStatus{Status: StatusFailing, Components: map[string]ComponentStatus{
+				"database": {Status: StatusFailing, Error: StatusFailing},
+				"worker":   {Status: StatusOK},
+			}},
+			wantLog: "connection refused",
+		},
+		"When a check times out": {
+			checks:     map[string]Check{"database": hanging},
+			wantStatus: http.StatusServiceUnavailable,
+			want: HealthStatus{Status: StatusFailing, Components: map[string]ComponentStatus{
+				"database": {Status: StatusFailing, Error: "timed out"},
+			}},
+			wantLog: "context deadline exceeded",
+		},
+		"When the server is shutting down": {
+			checks:       map[string]Check{"database": passing},
+			shuttingDown: true,
+			wantStatus:   http.StatusServiceUnavailable,
+			want:         HealthStatus{Status: StatusShuttingDown},
+		},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			logs := captureLogs(t)
+			health := NewHealth(10 * time.Millisecond)
+			for name, check := range tc.checks {
+				health.Register(name, check)
+			}
+			if tc.shuttingDown {
+				health.ShutDown()
+			}
+			server := gin.New()
+			server.GET("/livez", health.Live)
+			server.GET("/readyz", health.Ready)
+
+			res := httptest.NewRecorder()
+			server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if res.Code != tc.wantStatus {
+				t.Errorf("readyz returned %v, want %v", res.Code, tc.wantStatus)
+			}
+			var got HealthStatus
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			for name, component := range got.Components {
+				if component.LatencyMS < 0 {
+					t.Errorf("component %s has latency %v", name, component.LatencyMS)
+				}
+				component.LatencyMS = 0
+				got.Components[name] = component
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("readyz returned unexpected body:\ngot  %v\nwant %v\n", got, tc.want)
+			}
+			if tc.wantLog != "" && !strings.Contains(logs.String(), tc.wantLog) {
+				t.Errorf("the failure was not logged: %s", logs)
+			}
+
+			res = httptest.NewRecorder()
+			server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/livez", nil))
+			if res.Code != http.StatusOK || res.Body.String() != `{"status":"OK"}` {
+				t.Errorf("livez returned %v %s", res.Code, res.Body)
+			}
+		})
+	}
+}
+
+func TestHealth_RegisterTwice(t *testing.T) {
+	health := NewHealth(time.Second)
+	health.Register("database", func(ctx context.Context) error { return nil })
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice did not panic")
+		}
+	}()
+	health.Register("database", func(ctx context.Context) error { return nil })
+}
+
+func TestHeartbeat(t *testing.T) {
+	heartbeat := NewHeartbeat(time.Hour)
+	if err := heartbeat.Check(context.Background()); err != nil {
+		t.Errorf("a fresh heartbeat fails: %v", err)
+	}
+	heartbeat.last.Store(time.Now().Add(-3 * time.Hour).UnixNano())
+	if err := heartbeat.Check(context.Background()); err == nil {
+		t.Error("a heartbeat that missed two beats passes")
+	}
+	heartbeat.Beat()
+	if err := heartbeat.Check(context.Background()); err != nil {
+		t.Errorf("a heartbeat that beat again fails: %v", err)
+	}
+	heartbeat.Stop()
+	if err := heartbeat.Check(context.Background()); err == nil {
+		t.Error("a stopped heartbeat passes")
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader names the header a client sets to make a POST safe
+// to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// jsonContentType is the media type of the JSON responses gin writes.
+const jsonContentType = "application/json; charset=utf-8"
+
+// maxIdempotencyKeyLength is the size of the key column.
+const maxIdempotencyKeyLength = 255
+
+// Codes of problems with an Idempotency-Key.
+const (
+	codeIdempotencyKeyInvalid    = "idempotency_key_invalid"
+	codeIdempotencyKeyReused     = "idempotency_key_reused"
+	codeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+)
+
+// Keys of the gin context holding the Idempotency-Key reserved for the
+// request, and whether the handler already stored its response with it.
+const (
+	idempotencyKeyContextKey       = "idempotencyKey"
+	idempotencyCompletedContextKey = "idempotencyCompleted"
+)
+
+// WithIdempotencyKeys stores the responses of requests sent with an
+// Idempotency-Key in keys for ttl, so Idempotency can replay them.
+func WithIdempotencyKeys(keys domains.IdempotencyKeyDomain, ttl time.Duration) Option {
+	return func(h *BlogPostHandler) {
+		h.idempotencyKeys = keys
+		h.idempotencyTTL = ttl
+	}
+}
+
+// responseRecorder keeps a copy of the response body written through it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash identifies a request by its method, path and body, to tell a
+// retry from a different request reusing its Idempotency-Key.
+func requestHash(method, path string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + " " + path + "\n" + string(body)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Idempotency is middleware that makes the handlers after it run at most once
+// per Idempotency-Key. A retry with the same key and body gets the stored
+// response with Idempotent-Replayed set, one with a different body gets 422,
+// and one arriving while the first is still running gets 409. Responses with
+// a 5xx status are not stored, and neither are those of handlers that
+// panicked, so the request can be retried. Handlers storing the response
+// themselves, in the transaction of their changes, use respondOnce.
+// Requests without the header, or with no key storage configured, pass
+// through.
+func (h *BlogPostHandler) Idempotency(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if h.idempotencyKeys == nil || key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		writeProblem(c, http.StatusBadRequest, codeIdempotencyKeyInvalid,
+			fmt.Sprintf("the %s header exceeds %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength), nil)
+		return
+	}
+
+	data, err := h.readBody(c)
+	if err != nil && err != io.EOF {
+		writeValidationProblem(c, err)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	now := time.Now().UTC()
+	reservation := &models.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash(c.Request.Method, c.Request.URL.Path, data),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(h.idempotencyTTL),
+	}
+
+	ctx, cancel := operationContext(c, h.timeouts.Create)
+	existing, err := h.idempotencyKeys.ReserveIdempotencyKey(ctx, reservation)
+	cancel()
+	if err != nil {
+		writeDomainProblem(c, err)
+		return
+	}
+
+	if existing != nil {
+		switch {
+		case existing.RequestHash != reservation.RequestHash:
+			writeProblem(c, http.StatusUnprocessableEntity, codeIdempotencyKeyReused,
+				fmt.Sprintf("the %s was already used with a different request", IdempotencyKeyHeader), nil)
+		case existing.Status == 0:
+			writeProblem(c, http.StatusConflict, codeIdempotencyKeyInProgress,
+				fmt.Sprintf("a request with this %s is still being processed", IdempotencyKeyHeader), nil)
+		default:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(existing.Status, existing.ContentType, existing.Response)
+			c.Abort()
+		}
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Set(idempotencyKeyContextKey, key)
+
+	panicked := true
+	defer func() {
+		h.finishIdempotencyKey(c, key, recorder, panicked)
+	}()
+	c.Next()
+	panicked = false
+}
+
+// finishIdempotencyKey stores the response recorded for key, or releases
+// the key when the handler panicked or failed on the server. Nothing is
+// left to do when the handler stored the response itself.
+func (h *BlogPostHandler) finishIdempotencyKey(c *gin.Context, key string, recorder *responseRecorder, panicked bool) {
+	if c.GetBool(idempotencyCompletedContextKey) {
+		return
+	}
+
+	// The response is stored even when the client went away meanwhile, so
+	// its retry is answered from the key.
+	ctx := context.WithoutCancel(c.Request.Context())
+	if h.timeouts.Create > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeouts.Create)
+		defer cancel()
+	}
+	var err error
+	if status := recorder.Status(); panicked || status >= http.StatusInternalServerError {
+		err = h.idempotencyKeys.ReleaseIdempotencyKey(ctx, key)
+	} else {
+		err = h.idempotencyKeys.CompleteIdempotencyKey(ctx, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not store the response of an idempotency key", "key", key, "error", err)
+	}
+}
+
+// respondOnce runs fn, the work of a request, and writes the JSON response
+// it returns. When Idempotency reserved a key for the request, fn runs in a
+// transaction that also stores the response with the key, so a retry can
+// never repeat work whose response was lost. Otherwise fn runs on the
+// domains of h as they are.
+func (h *BlogPostHandler) respondOnce(c *gin.Context, ctx context.Context, fn func(tx domains.Repos) (int, any, error)) {
+	key := c.GetString(idempotencyKeyContextKey)
+	if key == "" {
+		status, body, err := fn(domains.Repos{BlogPosts: h.domain, IdempotencyKeys: h.idempotencyKeys})
+		if err != nil {
+			writeDomainProblem(c, err)
+			return
+		}
+		c.JSON(status, body)
+		return
+	}
+
+	var status int
+	var response []byte
+	err := h.withTx(ctx, func(tx domains.Repos) error {
+		var body any
+		var err error
+		if status, body, err = fn(tx); err != nil {
+			return err
+		}
+		if response, err = json.Marshal(body); err != nil {
+			return err
+		}
+		return tx.IdempotencyKeys.CompleteIdempotencyKey(ctx, key, status, jsonContentType, response)
+	})
+	if err != nil {
+		writeDomainProblem(c, err)
+		return
+	}
+	c.Set(idempotencyCompletedContextKey, true)
+	c.Data(status, jsonContentType, response)
+}
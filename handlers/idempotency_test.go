@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// countingService counts the blog posts created through it.
+type countingService struct {
+	*mock.FakeService
+	creates int
+}
+
+func (s *countingService) CreateBlogPost(ctx context.Context, blog *models.BlogPost) (*uuid.UUID, error) {
+	s.creates++
+	return s.FakeService.CreateBlogPost(ctx, blog)
+}
+
+// TestBlogPostHandler_Idempotency sends its requests in order, as each one
+// sees the keys stored by those before it.
+func TestBlogPostHandler_Idempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &countingService{FakeService: &mock.FakeService{}}
+	keys := domains.NewMemoryIdempotencyKeyDomain()
+
+	runner := &mock.FakeTxRunner{Repos: domains.Repos{BlogPosts: fakeDomain, IdempotencyKeys: keys}}
+	handler := NewBlogPostHandler(fakeDomain, WithIdempotencyKeys(keys, time.Hour), WithTxRunner(runner))
+	server.POST("/blog-post/", handler.Idempotency, handler.CreateBlogPost)
+
+	body := `{"title": "Valid Title", "description": "Valid Description", "body": "Valid Body"}`
+	otherBody := `{"title": "Other Title", "description": "Valid Description", "body": "Valid Body"}`
+	created := gin.H{"message": "blog post create successfully", "ID": mock.MockID.String()}
+
+	inProgress := "in-progress"
+	if _, err := keys.ReserveIdempotencyKey(context.Background(), &models.IdempotencyKey{
+		Key:         inProgress,
+		RequestHash: requestHash(http.MethodPost, "/blog-post/", []byte(body)),
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name     string
+		key      string
+		body     string
+		err      mock.ErrMock
+		status   int
+		replayed bool
+		creates  int
+		response gin.H
+	}{
+		{
+			name:     "When a key is first used",
+			key:      "first",
+			body:     body,
+			status:   http.StatusCreated,
+			creates:  1,
+			response: created,
+		},
+		{
+			name:     "When the request is retried",
+			key:      "first",
+			body:     body,
+			status:   http.StatusCreated,
+			replayed: true,
+			creates:  1,
+			response: created,
+		},
+		{
+			name:    "When the key is reused with a different body",
+			key:     "first",
+			body:    otherBody,
+			status:  http.StatusUnprocessableEntity,
+			creates: 1,
+			response: domainProblem(http.StatusUnprocessableEntity, "idempotency_key_reused",
+				"the Idempotency-Key was already used with a different request", "/blog-post/"),
+		},
+		{
+			name:    "When the first request is still running",
+			key:     inProgress,
+			body:    body,
+			status:  http.StatusConflict,
+			creates: 1,
+			response: domainProblem(http.StatusConflict, "idempotency_key_in_progress",
+				"a request with this Idempotency-Key is still being processed", "/blog-post/"),
+		},
+		{
+			name:    "When the request fails on the server",
+			key:     "failing",
+			body:    body,
+			err:     mock.DBOperationError,
+			status:  http.StatusInternalServerError,
+			creates: 2,
+			response: domainProblem(http.StatusInternalServerError, "create_blog_post_failed",
+				domains.ErrorCreateBlogPostFailed.Error(), "/blog-post/"),
+		},
+		{
+			name:     "When the failed request is retried",
+			key:      "failing",
+			body:     body,
+			status:   http.StatusCreated,
+			creates:  3,
+			response: created,
+		},
+		{
+			name:    "When the request is invalid",
+			key:     "invalid",
+			body:    `{"title": "Valid Title"}`,
+			status:  http.StatusBadRequest,
+			creates: 3,
+			response: validationProblem("/blog-post/",
+				gin.H{"field": "description", "code": "required", "message": "is required"},
+				gin.H{"field": "body", "code": "required", "message": "is required"},
+			),
+		},
+		{
+			name:     "When the invalid request is retried",
+			key:      "invalid",
+			body:     `{"title": "Valid Title"}`,
+			status:   http.StatusBadRequest,
+			replayed: true,
+			creates:  3,
+			response: validationProblem("/blog-post/",
+				gin.H{"field": "description", "code": "required", "message": "is required"},
+				gin.H{"field": "body", "code": "required", "message": "is required"},
+			),
+		},
+		{
+			name:    "When the key is too long",
+			key:     strings.Repeat("k", 256),
+			body:    body,
+			status:  http.StatusBadRequest,
+			creates: 3,
+			response: domainProblem(http.StatusBadRequest, "idempotency_key_invalid",
+				"the Idempotency-Key header exceeds 255 characters", "/blog-post/"),
+		},
+		{
+			name:     "When no key is sent",
+			body:     body,
+			status:   http.StatusCreated,
+			creates:  4,
+			response: created,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			fakeDomain.Err = step.err
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/blog-post/", strings.NewReader(step.body))
+			req.Header.Set("Content-Type", "application/json")
+			if step.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, step.key)
+			}
+			server.ServeHTTP(res, req)
+
+			if res.Code != step.status {
+				t.Errorf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, step.status)
+			}
+			if replayed := res.Header().Get("Idempotent-Replayed") == "true"; replayed != step.replayed {
+				t.Errorf("handler returned Idempotent-Replayed %v, want %v", replayed, step.replayed)
+			}
+			if fakeDomain.creates != step.creates {
+				t.Errorf("domain created %d posts, want %d", fakeDomain.creates, step.creates)
+			}
+
+			var got gin.H
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(step.response) {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, step.response)
+			}
+		})
+	}
+}
+
+func TestBlogPostHandler_IdempotencyExpires(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &countingService{FakeService: &mock.FakeService{}}
+
+	keys := domains.NewMemoryIdempotencyKeyDomain()
+	runner := &mock.FakeTxRunner{Repos: domains.Repos{BlogPosts: fakeDomain, IdempotencyKeys: keys}}
+	handler := NewBlogPostHandler(fakeDomain, WithIdempotencyKeys(keys, time.Nanosecond), WithTxRunner(runner))
+	server.POST("/blog-post/", handler.Idempotency, handler.CreateBlogPost)
+
+	for _, title := range []string{"Valid Title", "Other Title"} {
+		res := httptest.NewRecorder()
+		body := `{"title": "` + title + `", "description": "Valid Description", "body": "Valid Body"}`
+		req := httptest.NewRequest(http.MethodPost, "/blog-post/", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "expiring")
+		time.Sleep(time.Millisecond)
+		server.ServeHTTP(res, req)
+
+		if res.Code != http.StatusCreated || res.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("handler returned %v, replayed %q; want a new post", res.Code, res.Header().Get("Idempotent-Replayed"))
+		}
+	}
+	if fakeDomain.creates != 2 {
+		t.Errorf("domain created %d posts, want 2", fakeDomain.creates)
+	}
+}
+
+// failingKeys cannot store responses.
+type failingKeys struct {
+	domains.IdempotencyKeyDomain
+}
+
+func (k failingKeys) CompleteIdempotencyKey(ctx context.Context, key string, status int, contentType string, response []byte) error {
+	return domains.ErrorIdempotencyKeyFailed
+}
+
+// TestBlogPostHandler_IdempotencyAtomic checks that a post is only created
+// along with the response stored for its key.
+func TestBlogPostHandler_IdempotencyAtomic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	domain := domains.NewMemoryBlogPostDomain()
+	keys := domains.NewMemoryIdempotencyKeyDomain()
+	runner := domains.NewMemoryTxRunner(domain, keys)
+
+	handler := NewBlogPostHandler(domain, WithIdempotencyKeys(keys, time.Hour), WithTxRunner(runner))
+	server.POST("/blog-post/", handler.Idempotency, handler.CreateBlogPost)
+
+	failing := NewBlogPostHandler(domain, WithIdempotencyKeys(keys, time.Hour),
+		WithTxRunner(&mock.FakeTxRunner{Repos: domains.Repos{BlogPosts: domain, IdempotencyKeys: failingKeys{keys}}}))
+	server.POST("/failing/", failing.Idempotency, failing.CreateBlogPost)
+
+	body := `{"title": "Valid Title", "description": "Valid Description", "body": "Valid Body"}`
+	send := func(path string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "atomic")
+		server.ServeHTTP(res, req)
+		return res
+	}
+
+	if res := send("/failing/"); res.Code != http.StatusInternalServerError {
+		t.Errorf("handler returned %v when the response could not be stored, want 500", res.Code)
+	}
+	first := send("/blog-post/")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("retry returned %v, want 201", first.Code)
+	}
+	retry := send("/blog-post/")
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Body.String() != first.Body.String() {
+		t.Errorf("second retry was not replayed: %v %s", retry.Code, retry.Body)
+	}
+	if retry.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replay has Content-Type %q, want %q", retry.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+}
+
+func TestBlogPostHandler_IdempotencyPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	captureLogs(t)
+	server := gin.New()
+	server.Use(gin.CustomRecoveryWithWriter(io.Discard, Recover))
+	handler := NewBlogPostHandler(&mock.FakeService{}, WithIdempotencyKeys(domains.NewMemoryIdempotencyKeyDomain(), time.Hour))
+
+	calls := 0
+	server.POST("/blog-post/", handler.Idempotency, func(c *gin.Context) {
+		if calls++; calls == 1 {
+			panic("boom")
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, want := range []int{http.StatusInternalServerError, http.StatusNoContent} {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/blog-post/", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "panicking")
+		server.ServeHTTP(res, req)
+		if res.Code != want {
+			t.Errorf("handler returned %v, want %v", res.Code, want)
+		}
+	}
+}
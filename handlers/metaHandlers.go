@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/gin-gonic/gin"
+)
+
+// fieldRule describes how one field of a create request is validated.
+type fieldRule struct {
+	Required bool `json:"required"`
+	config.LengthRule
+}
+
+// GetValidationRules returns the rules create and update requests are
+// validated against, so clients can check input before sending it. Update
+// requests apply the same lengths to the fields they contain.
+func (h *BlogPostHandler) GetValidationRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"fields": gin.H{
+			"title":       fieldRule{Required: true, LengthRule: h.rules.Title},
+			"description": fieldRule{Required: true, LengthRule: h.rules.Description},
+			"body":        fieldRule{Required: true, LengthRule: h.rules.Body},
+		},
+	})
+}
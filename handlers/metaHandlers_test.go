@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBlogPostHandler_ValidationRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	fakeDomain := &mock.FakeService{}
+
+	rules := config.ValidationRules{
+		Title:       config.LengthRule{Min: 3, Max: 10},
+		Description: config.LengthRule{Min: 1, Max: 20},
+		Body:        config.LengthRule{Min: 2},
+	}
+	handler := NewBlogPostHandler(fakeDomain, WithValidationRules(rules))
+	server.GET("/meta/validation", handler.GetValidationRules)
+	server.POST("/blog-post", handler.CreateBlogPost)
+
+	t.Run("When rules are requested", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/meta/validation", nil))
+
+		if res.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, http.StatusOK)
+		}
+		want := gin.H{
+			"fields": gin.H{
+				"title":       gin.H{"required": true, "min_length": 3, "max_length": 10},
+				"description": gin.H{"required": true, "min_length": 1, "max_length": 20},
+				"body":        gin.H{"required": true, "min_length": 2},
+			},
+		}
+		var got gin.H
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, want)
+		}
+	})
+
+	t.Run("When a post breaks the configured rules", func(t *testing.T) {
+		body, err := json.Marshal(gin.H{
+			"title":       "A title that is too long",
+			"description": "Short",
+			"body":        "B",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/blog-post", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		server.ServeHTTP(res, req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("handler returned wrong status code:\ngot  %v\nwant %v\n", res.Code, http.StatusBadRequest)
+		}
+		want := validationProblem("/blog-post",
+			gin.H{"field": "title", "code": "max", "message": "should not exceed 10 characters"},
+			gin.H{"field": "body", "code": "min", "message": "should at least have 2 characters"},
+		)
+		var got gin.H
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, want)
+		}
+	})
+}
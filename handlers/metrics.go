@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPMetrics returns middleware that records every request in m, labeled
+// with the route template rather than the path so the number of series stays
+// bounded.
+func HTTPMetrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/metrics"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+	server := gin.New()
+	server.Use(HTTPMetrics(m))
+
+	handler := NewBlogPostHandler(&mock.FakeService{})
+	server.GET("/blog-post/:ID", handler.GetBlogPost)
+
+	for _, path := range []string{
+		"/blog-post/" + mock.MockID.String(),
+		"/blog-post/not-a-uuid",
+		"/nowhere",
+	} {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	res := httptest.NewRecorder()
+	m.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`blogassessment_http_requests_total{method="GET",route="/blog-post/:ID",status="200"} 1`,
+		`blogassessment_http_requests_total{method="GET",route="/blog-post/:ID",status="400"} 1`,
+		`blogassessment_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(res.Body.String(), want) {
+			t.Errorf("metrics are missing %q", want)
+		}
+	}
+	if strings.Contains(res.Body.String(), "not-a-uuid") {
+		t.Error("metrics are labeled with the raw path")
+	}
+}
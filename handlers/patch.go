@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/DurgeshKr2242/blogassessment/patch"
+	"github.com/DurgeshKr2242/blogassessment/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Media types of PATCH bodies besides application/json.
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// applyUpdateRequest sets the fields present in an UpdateBlogPostRequest
+// body on blog.
+func (h *BlogPostHandler) applyUpdateRequest(c *gin.Context, blog *models.BlogPost) error {
+	var req models.UpdateBlogPostRequest
+	if err := h.bindJSON(c, &req); err != nil {
+		return err
+	}
+	applyUpdate(req, blog)
+	return nil
+}
+
+// applyUpdate sets the fields present in req on blog. PATCH requests and
+// the update operations of a batch share it.
+func applyUpdate(req models.UpdateBlogPostRequest, blog *models.BlogPost) {
+	if req.Title != nil {
+		blog.Title = *req.Title
+	}
+	if req.Description != nil {
+		blog.Description = *req.Description
+	}
+	if req.Body != nil {
+		blog.Body = *req.Body
+	}
+	if req.Slug != nil {
+		blog.Slug = *req.Slug
+	}
+	if req.Tags != nil {
+		blog.Tags = *req.Tags
+	}
+}
+
+// applyPatch applies a merge patch or JSON patch body to the editable
+// fields of blog. The patched post is validated like a created one, so
+// required fields can't be removed.
+func (h *BlogPostHandler) applyPatch(c *gin.Context, contentType string, blog *models.BlogPost) error {
+	data, err := h.readBody(c)
+	if err != nil {
+		return err
+	}
+	// Rejects duplicate keys and trailing data in the patch itself.
+	var raw any
+	if err := validation.DecodeStrict(data, &raw); err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(blog.Editable())
+	if err != nil {
+		return err
+	}
+	if contentType == mergePatchContentType {
+		doc, err = patch.Merge(doc, data)
+	} else {
+		var ops []patch.Operation
+		if ops, err = patch.ParseOperations(data); err != nil {
+			return err
+		}
+		doc, err = patch.Apply(doc, ops)
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.applyEditable(doc, blog)
+}
+
+// applyEditable strictly decodes an EditableBlogPost document, validates it
+// like a created post and sets its fields on blog.
+func (h *BlogPostHandler) applyEditable(doc []byte, blog *models.BlogPost) error {
+	var edited models.EditableBlogPost
+	if err := validation.DecodeStrict(doc, &edited); err != nil {
+		return err
+	}
+	if err := h.validator.Struct(edited); err != nil {
+		return err
+	}
+	if err := h.validator.Struct(models.CreateBlogPostRequest{
+		Title:       edited.Title,
+		Description: edited.Description,
+		Body:        edited.Body,
+	}); err != nil {
+		return err
+	}
+
+	blog.Title = edited.Title
+	blog.Description = edited.Description
+	blog.Body = edited.Body
+	blog.Slug = edited.Slug
+	blog.Tags = edited.Tags
+	blog.Draft = edited.Draft
+	return nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/patch"
+	"github.com/DurgeshKr2242/blogassessment/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of error responses.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of a problem to form its type URI.
+const problemTypePrefix = "urn:blogassessment:problem:"
+
+// Codes of problems caused by an invalid request.
+const (
+	codeValidationFailed     = "validation_failed"
+	codeBodyTooLarge         = "body_too_large"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codePatchInvalid         = "patch_invalid"
+	codePatchPathNotFound    = "patch_path_not_found"
+	codePatchTestFailed      = "patch_test_failed"
+	codePreconditionFailed   = "precondition_failed"
+)
+
+// codeInternal is the problem code of a failure with no more specific one,
+// the same domains.ErrorCode gives unknown errors.
+const codeInternal = "internal"
+
+// Problem is an RFC 9457 problem details object. Code repeats the last
+// segment of Type so clients can switch on it without parsing the URI.
+type Problem struct {
+	Type     string                  `json:"type"`
+	Title    string                  `json:"title"`
+	Status   int                     `json:"status"`
+	Detail   string                  `json:"detail,omitempty"`
+	Instance string                  `json:"instance,omitempty"`
+	Code     string                  `json:"code"`
+	Errors   []validation.FieldError `json:"errors,omitempty"`
+}
+
+// writeProblem aborts the request with a problem+json response.
+func writeProblem(c *gin.Context, status int, code, detail string, errs []validation.FieldError) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+		Errors:   errs,
+	})
+}
+
+// writeDomainProblem reports an error returned by the domain. Clients get
+// the message of its sentinel, and the log the database error behind it.
+func writeDomainProblem(c *gin.Context, err error) {
+	writeDomainProblemDetail(c, err, domains.Message(err))
+}
+
+// writeDomainProblemDetail is writeDomainProblem with a detail of its own.
+func writeDomainProblemDetail(c *gin.Context, err error, detail string) {
+	status := domainErrorStatus(err)
+	level := slog.LevelDebug
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(c.Request.Context(), level, "Domain operation failed", "status", status, "error", err)
+	writeProblem(c, status, domains.ErrorCode(err), detail, nil)
+}
+
+// writeValidationProblem reports a request that failed to bind, or a patch
+// that could not be applied, with field messages in the language the client
+// prefers.
+func writeValidationProblem(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeProblem(c, http.StatusRequestEntityTooLarge, codeBodyTooLarge,
+			fmt.Sprintf("the request body exceeds %d bytes", tooLarge.Limit), nil)
+		return
+	}
+
+	var patchErr *patch.Error
+	if errors.As(err, &patchErr) {
+		switch {
+		case errors.Is(err, patch.ErrTestFailed):
+			writeProblem(c, http.StatusConflict, codePatchTestFailed, err.Error(), nil)
+		case errors.Is(err, patch.ErrPathNotFound):
+			writeProblem(c, http.StatusUnprocessableEntity, codePatchPathNotFound, err.Error(), nil)
+		default:
+			writeProblem(c, http.StatusBadRequest, codePatchInvalid, err.Error(), nil)
+		}
+		return
+	}
+
+	languages := validation.AcceptLanguage(c.GetHeader("Accept-Language"))
+	writeProblem(c, http.StatusBadRequest, codeValidationFailed,
+		"the request is invalid", validation.CustomValidationError(err, languages...))
+}
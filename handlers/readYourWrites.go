@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadYourWritesCookie and ReadYourWritesHeader carry the time, in Unix
+// milliseconds, until which a client that wrote reads from the primary.
+const (
+	ReadYourWritesCookie = "read_your_writes"
+	ReadYourWritesHeader = "X-Read-Your-Writes"
+)
+
+// ReadYourWrites returns middleware that sends the reads of a client to the
+// primary database for window after it writes, so it sees its own changes
+// despite replica lag. Writes read from the primary too, and hand the end
+// of the window to the client both as a cookie and in the
+// X-Read-Your-Writes header; clients that don't keep cookies send the header
+// back with their reads.
+func ReadYourWrites(window time.Duration) gin.HandlerFunc {
+	maxAge := max(1, int(math.Ceil(window.Seconds())))
+	return func(c *gin.Context) {
+		now := time.Now()
+		primary := false
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			primary = readYourWritesUntil(c, now, window).After(now)
+		default:
+			until := strconv.FormatInt(now.Add(window).UnixMilli(), 10)
+			c.Header(ReadYourWritesHeader, until)
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     ReadYourWritesCookie,
+				Value:    until,
+				Path:     "/",
+				MaxAge:   maxAge,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+			primary = true
+		}
+		if primary {
+			c.Request = c.Request.WithContext(domains.WithPrimaryReads(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
+
+// readYourWritesUntil returns the end of the read-your-writes window the
+// client sent, in the header or else the cookie, or the zero time. No write
+// hands out a window ending after now+window, so later ends are cut to it.
+func readYourWritesUntil(c *gin.Context, now time.Time, window time.Duration) time.Time {
+	value := c.GetHeader(ReadYourWritesHeader)
+	if value == "" {
+		value, _ = c.Cookie(ReadYourWritesCookie)
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	if limit := now.Add(window); ms > limit.UnixMilli() {
+		return limit
+	}
+	return time.UnixMilli(ms)
+}
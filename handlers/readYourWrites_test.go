@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	server.Use(ReadYourWrites(5 * time.Second))
+	server.Any("/", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatBool(domains.PrimaryReads(c.Request.Context())))
+	})
+
+	future := strconv.FormatInt(time.Now().Add(time.Minute).UnixMilli(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).UnixMilli(), 10)
+	cases := map[string]struct {
+		method      string
+		header      string
+		cookie      string
+		wantPrimary bool
+		wantWindow  bool
+	}{
+		"When the client writes":                  {method: http.MethodPost, wantPrimary: true, wantWindow: true},
+		"When the client deletes":                 {method: http.MethodDelete, wantPrimary: true, wantWindow: true},
+		"When the client reads without a window":  {method: http.MethodGet},
+		"When the client reads within the header": {method: http.MethodGet, header: future, wantPrimary: true},
+		"When the client reads within the cookie": {method: http.MethodGet, cookie: future, wantPrimary: true},
+		"When the window has passed":              {method: http.MethodGet, header: past},
+		"When the window is malformed":            {method: http.MethodGet, cookie: "soon"},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(ReadYourWritesHeader, tc.header)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: ReadYourWritesCookie, Value: tc.cookie})
+			}
+			res := httptest.NewRecorder()
+			server.ServeHTTP(res, req)
+
+			if got := res.Body.String(); got != strconv.FormatBool(tc.wantPrimary) {
+				t.Errorf("primary reads: %s, want %v", got, tc.wantPrimary)
+			}
+			header := res.Header().Get(ReadYourWritesHeader)
+			if (header != "") != tc.wantWindow {
+				t.Fatalf("handler returned window %q", header)
+			}
+			if !tc.wantWindow {
+				return
+			}
+			until, err := strconv.ParseInt(header, 10, 64)
+			if err != nil || time.Until(time.UnixMilli(until)) <= 4*time.Second {
+				t.Errorf("handler returned window until %q", header)
+			}
+			cookies := res.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Name != ReadYourWritesCookie || cookies[0].Value != header || cookies[0].MaxAge != 5 {
+				t.Errorf("handler set cookies %v", cookies)
+			}
+		})
+	}
+}
+
+func TestReadYourWritesUntil(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	window := 5 * time.Second
+
+	cases := map[string]struct {
+		header string
+		want   time.Time
+	}{
+		"When the window is within the limit": {
+			header: strconv.FormatInt(now.Add(time.Second).UnixMilli(), 10),
+			want:   time.UnixMilli(now.Add(time.Second).UnixMilli()),
+		},
+		"When the window ends too late": {
+			header: strconv.FormatInt(now.Add(24*time.Hour).UnixMilli(), 10),
+			want:   now.Add(window),
+		},
+		"When the window is at the end of time": {
+			header: strconv.FormatInt(math.MaxInt64, 10),
+			want:   now.Add(window),
+		},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set(ReadYourWritesHeader, tc.header)
+
+			if got := readYourWritesUntil(c, now, window); !got.Equal(tc.want) {
+				t.Errorf("window ends at %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
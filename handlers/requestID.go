@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the client or
+// generated, in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// RequestID is middleware that takes the request ID from the X-Request-ID
+// header, or generates one when it is missing or not a short printable
+// token, echoes it in the response and attaches it to the request context,
+// so every line logged for the request holds it.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID(id) {
+		id = uuid.NewString()
+	}
+	c.Header(RequestIDHeader, id)
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+	c.Next()
+}
+
+// validRequestID reports whether id can go into log lines as it is.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog is middleware that logs every request once it is handled, with
+// the route template rather than the path so lines group by endpoint.
+func AccessLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(c.Request.Context(), level, "Request handled",
+		"method", c.Request.Method,
+		"route", c.FullPath(),
+		"path", c.Request.URL.Path,
+		"status", status,
+		"duration", time.Since(start),
+		"bytes", c.Writer.Size(),
+	)
+}
+
+// Recover reports a handler that panicked as a 500 problem and logs the
+// panic. It is meant for gin.CustomRecoveryWithWriter.
+func Recover(c *gin.Context, recovered any) {
+	slog.ErrorContext(c.Request.Context(), "Request panicked", "panic", recovered, "stack", string(debug.Stack()))
+	writeProblem(c, http.StatusInternalServerError, codeInternal, "the request could not be handled", nil)
+}
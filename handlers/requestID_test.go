@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/logging"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// captureLogs sends the default logger to a buffer until the test ends.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, config.LogFormatJSON, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	server.Use(RequestID)
+	server.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+	})
+
+	cases := map[string]struct {
+		header   string
+		generate bool
+	}{
+		"When the client sends an ID":       {header: "req-42"},
+		"When the client sends none":        {generate: true},
+		"When the ID holds a line break":    {header: "req\nforged=1", generate: true},
+		"When the ID is too long":           {header: strings.Repeat("x", 129), generate: true},
+		"When the ID is the longest kept":   {header: strings.Repeat("x", 128)},
+		"When the ID holds printable chars": {header: "a.b-c_d:e/1"},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+			server.ServeHTTP(res, req)
+
+			id := res.Header().Get(RequestIDHeader)
+			if tc.generate {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("expected a generated UUID, got %q", id)
+				}
+			} else if id != tc.header {
+				t.Errorf("handler returned ID %q, want %q", id, tc.header)
+			}
+			if res.Body.String() != id {
+				t.Errorf("context holds ID %q, want %q", res.Body.String(), id)
+			}
+		})
+	}
+}
+
+// failingService fails to get a post with a database error behind the
+// sentinel.
+type failingService struct {
+	*mock.FakeService
+}
+
+func (s *failingService) GetBlogPost(ctx context.Context, ID *uuid.UUID) (*models.BlogPost, error) {
+	return nil, fmt.Errorf("%w: %w", domains.ErrorGetBlogPostFailed, errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+}
+
+func TestWriteDomainProblem_LogsCause(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := captureLogs(t)
+	server := gin.New()
+	server.Use(RequestID, AccessLog)
+
+	handler := NewBlogPostHandler(&failingService{&mock.FakeService{}})
+	server.GET("/blog-post/:ID", handler.GetBlogPost)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/blog-post/"+mock.MockID.String(), nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	server.ServeHTTP(res, req)
+
+	var got gin.H
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := domainProblem(http.StatusInternalServerError, "get_blog_post_failed",
+		"failed to get blog post", "/blog-post/"+mock.MockID.String())
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v\n", got, want)
+	}
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, entry)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %v", lines)
+	}
+	failure, access := lines[0], lines[1]
+	if failure["level"] != "ERROR" || failure[logging.RequestIDKey] != "req-42" ||
+		!strings.Contains(fmt.Sprint(failure["error"]), "connection refused") {
+		t.Errorf("unexpected failure line: %v", failure)
+	}
+	if access[logging.RequestIDKey] != "req-42" || access["route"] != "/blog-post/:ID" || access["status"] != float64(500) {
+		t.Errorf("unexpected access line: %v", access)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := captureLogs(t)
+	server := gin.New()
+	server.Use(RequestID, gin.CustomRecoveryWithWriter(io.Discard, Recover))
+	server.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	res := httptest.NewRecorder()
+	server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if res.Code != http.StatusInternalServerError || res.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf("handler returned %v with %q", res.Code, res.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(logs.String(), `"panic":"boom"`) {
+		t.Errorf("panic was not logged: %s", logs)
+	}
+}
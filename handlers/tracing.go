@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the server spans of requests.
+const tracerName = "github.com/DurgeshKr2242/blogassessment/handlers"
+
+// Tracing is middleware that handles every request in a server span. The
+// span continues the trace of the traceparent header when there is one, and
+// is named after the route template so spans group by endpoint.
+func Tracing(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+	route := c.FullPath()
+	name := c.Request.Method
+	if route != "" {
+		name += " " + route
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.URLPath(c.Request.URL.Path),
+		),
+	)
+	defer span.End()
+	if route != "" {
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
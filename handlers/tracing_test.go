@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/DurgeshKr2242/blogassessment/tracing/tracingtest"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	server.Use(Tracing)
+
+	handler := NewBlogPostHandler(&failingService{&mock.FakeService{}})
+	server.GET("/blog-post/:ID", handler.GetBlogPost)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	cases := map[string]struct {
+		path        string
+		traceparent string
+		name        string
+		status      int
+		code        codes.Code
+	}{
+		"When the client continues a trace": {
+			path:        "/blog-post/" + mock.MockID.String(),
+			traceparent: "00-" + traceID + "-00f067aa0ba902b7-01",
+			name:        "GET /blog-post/:ID",
+			status:      http.StatusInternalServerError,
+			code:        codes.Error,
+		},
+		"When the request is invalid": {
+			path:   "/blog-post/not-a-uuid",
+			name:   "GET /blog-post/:ID",
+			status: http.StatusBadRequest,
+			code:   codes.Unset,
+		},
+		"When no route matches": {
+			path:   "/nowhere",
+			name:   "GET",
+			status: http.StatusNotFound,
+			code:   codes.Unset,
+		},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			recorder := tracingtest.Record(t)
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.traceparent != "" {
+				req.Header.Set("traceparent", tc.traceparent)
+			}
+			server.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tc.name || span.SpanKind() != trace.SpanKindServer || span.Status().Code != tc.code {
+				t.Errorf("unexpected span %q of kind %v with status %v", span.Name(), span.SpanKind(), span.Status())
+			}
+			if tc.traceparent != "" && (span.SpanContext().TraceID().String() != traceID || !span.Parent().IsRemote()) {
+				t.Errorf("span does not continue trace %s: %v", traceID, span.SpanContext().TraceID())
+			}
+			if tc.traceparent == "" && span.Parent().IsValid() {
+				t.Errorf("span has an unexpected parent")
+			}
+			attrs := attribute.NewSet(span.Attributes()...)
+			if status, _ := attrs.Value("http.response.status_code"); status.AsInt64() != int64(tc.status) {
+				t.Errorf("span has status code %v, want %v", status.AsInt64(), tc.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/DurgeshKr2242/blogassessment/transfer"
+)
+
+// runImport implements `blogassessment import [-dry-run] <path>`, which
+// creates or updates posts from a directory of Markdown files, a single
+// Markdown file, an archive written by the export command, or a WordPress
+// WXR export. Posts breaking the configured validation rules fail, as they
+// would through the API.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print what would change without writing anything")
+	attachmentBaseURL := flags.String("attachment-base-url", "", "WXR only: base URL replacing the WordPress uploads URL in post bodies")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: blogassessment import [flags] <dir|file.md|export.tar.gz|export.ndjson|wordpress.xml>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	path := flags.Arg(0)
+	var items []transfer.Item
+	var errs map[string]error
+	var skipped []transfer.Result
+	if strings.HasSuffix(strings.ToLower(path), ".xml") {
+		items, skipped, err = readWXR(path, transfer.WXROptions{AttachmentBaseURL: *attachmentBaseURL, Rules: cfg.Validation})
+	} else {
+		items, errs, err = transfer.Load(path)
+	}
+	if err != nil {
+		log.Printf("Could not read %s: %v", path, err)
+		return 1
+	}
+
+	domain, closeDB, err := openBlogPostDomain(cfg)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer closeDB()
+
+	importer := transfer.NewImporter(domain, cfg.Validation, *dryRun).OnProgress(func(done, total int, r transfer.Result) {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s %s\n", done, total, r.Action, r.Slug)
+	})
+	summary := importer.Import(context.Background(), items)
+	summary.AddSkipped(skipped)
+	summary.AddFailures(errs)
+	summary.Print(os.Stdout)
+
+	if summary.Count(transfer.ActionFailed) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func readWXR(path string, opts transfer.WXROptions) ([]transfer.Item, []transfer.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return transfer.ReadWXR(f, path, opts)
+}
@@ -0,0 +1,70 @@
+// Package logging sets up the structured logger of the server and carries
+// the request ID of a request to every line logged while handling it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDKey is the attribute that holds the request ID on log lines.
+const RequestIDKey = "request_id"
+
+// TraceIDKey is the attribute that holds the ID of the trace on log lines.
+const TraceIDKey = "trace_id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// New returns a logger writing lines at level and above to w, as JSON when
+// format is config.LogFormatJSON and as text otherwise.
+// Lines logged with a context carrying a request ID hold it as request_id,
+// and those logged inside a recorded span the ID of its trace as trace_id.
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if format == config.LogFormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// contextHandler adds the request and trace IDs of the context to each
+// record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
+		r.AddAttrs(slog.String(TraceIDKey, span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
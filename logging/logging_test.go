@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogFormatJSON, slog.LevelInfo).With("component", "test")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.DebugContext(ctx, "hidden")
+	logger.InfoContext(ctx, "shown", "n", 1)
+	logger.Info("without request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", lines)
+	}
+	var first, second map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if first["msg"] != "shown" || first[RequestIDKey] != "req-1" || first["component"] != "test" {
+		t.Errorf("unexpected line: %v", first)
+	}
+	if _, ok := second[RequestIDKey]; ok {
+		t.Errorf("line without a request ID holds one: %v", second)
+	}
+}
+
+func TestNewText(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, config.LogFormatText, slog.LevelDebug).DebugContext(WithRequestID(context.Background(), "req-2"), "hello")
+	if line := buf.String(); !strings.Contains(line, "msg=hello") || !strings.Contains(line, "request_id=req-2") {
+		t.Errorf("unexpected line: %q", line)
+	}
+}
+
+func TestNewTraceID(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	span := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	for name, tc := range map[string]struct {
+		flags trace.TraceFlags
+		want  bool
+	}{
+		"sampled":     {trace.FlagsSampled, true},
+		"not sampled": {0, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := trace.ContextWithSpanContext(context.Background(), span.WithTraceFlags(tc.flags))
+			New(&buf, config.LogFormatText, slog.LevelInfo).InfoContext(ctx, "hello")
+			if got := strings.Contains(buf.String(), "trace_id="+traceID.String()); got != tc.want {
+				t.Errorf("line %q holds the trace ID: %v, want %v", buf.String(), got, tc.want)
+			}
+		})
+	}
+}
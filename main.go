@@ -1,45 +1,285 @@
 package main
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // time zones for ?tz= on hosts without a zoneinfo database
 
 	"github.com/DurgeshKr2242/blogassessment/config"
 	"github.com/DurgeshKr2242/blogassessment/db"
-	`github.com/DurgeshKr2242/blogassessment/domains`
+	"github.com/DurgeshKr2242/blogassessment/domains"
 	"github.com/DurgeshKr2242/blogassessment/handlers"
+	"github.com/DurgeshKr2242/blogassessment/logging"
+	"github.com/DurgeshKr2242/blogassessment/metrics"
 	"github.com/DurgeshKr2242/blogassessment/router"
+	"github.com/DurgeshKr2242/blogassessment/tracing"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		}
+	}
+	os.Exit(runServer())
+}
+
+// runServer serves the API until SIGINT or SIGTERM, then shuts down: /readyz
+// starts failing, in-flight requests are drained, background workers are
+// stopped and the database is closed, in that order.
+func runServer() int {
 	// 1. Load Configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		slog.Error("Failed to load config", "error", err)
+		return 1
 	}
+	slog.SetDefault(logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel))
 
-	// 2. Connect to Database
-	database, err := db.ConnectDB(cfg)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
 	if err != nil {
-		log.Fatalf("Could not connect to database: %v", err)
+		slog.Error("Could not set up tracing", "error", err)
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Could not flush spans", "error", err)
+		}
+	}()
+
+	m := metrics.New()
+	health := handlers.NewHealth(cfg.HealthCheckTimeout)
+
+	// 2. Initialize Domains (database interactions)
+	var blogPostDomain domains.BlogPostDomain
+	var idempotencyKeyDomain domains.IdempotencyKeyDomain
+	var txRunner domains.TxRunner
+	var replicas *domains.Replicas
+	switch cfg.Storage {
+	case config.StorageMemory:
+		slog.Warn("Using in-memory storage, blog posts are lost on restart")
+		blogPostDomain = domains.NewMemoryBlogPostDomain()
+		idempotencyKeyDomain = domains.NewMemoryIdempotencyKeyDomain()
+		txRunner = domains.NewMemoryTxRunner(blogPostDomain, idempotencyKeyDomain)
+
+	default:
+		// 3. Connect to Database
+		database, err := db.ConnectDB(cfg)
+		if err != nil {
+			slog.Error("Could not connect to database", "error", err)
+			return 1
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				slog.Error("Could not close database", "error", err)
+			}
+		}()
+		if err := m.RegisterDB(database, cfg.Storage); err != nil {
+			slog.Error("Could not register database metrics", "error", err)
+			return 1
+		}
+
+		// 4. Apply Migrations
+		migrator, err := db.NewMigrator(database, cfg.Storage)
+		if err != nil {
+			slog.Error("Could not load migrations", "error", err)
+			return 1
+		}
+		if cfg.AutoMigrate {
+			if err := migrator.Up(context.Background()); err != nil {
+				slog.Error("Could not apply migrations", "error", err)
+				return 1
+			}
+		}
+
+		health.Register("database", database.PingContext)
+		health.Register("migrations", migrator.Check)
+
+		if cfg.Storage == config.StorageSQLite {
+			blogPostDomain = domains.NewSQLiteBlogPostDomain(database)
+			idempotencyKeyDomain = domains.NewSQLiteIdempotencyKeyDomain(database)
+			txRunner = domains.NewSQLiteTxRunner(database)
+		} else if len(cfg.ReplicaURLs) > 0 {
+			replicaDBs := make([]*sql.DB, 0, len(cfg.ReplicaURLs))
+			for i, url := range cfg.ReplicaURLs {
+				replica, err := db.OpenReplica(url, cfg.Pool)
+				if err != nil {
+					slog.Error("Could not open read replica", "replica", i, "error", err)
+					return 1
+				}
+				defer replica.Close()
+				if err := m.RegisterDB(replica, fmt.Sprintf("%s-replica-%d", cfg.Storage, i)); err != nil {
+					slog.Error("Could not register database metrics", "error", err)
+					return 1
+				}
+				replicaDBs = append(replicaDBs, replica)
+			}
+			replicas = domains.NewReplicas(replicaDBs...)
+			blogPostDomain = domains.NewReplicatedBlogPostDomain(database, replicas)
+			idempotencyKeyDomain = domains.NewIdempotencyKeyDomain(database)
+			txRunner = domains.NewTxRunner(database)
+		} else {
+			blogPostDomain = domains.NewBlogPostDomain(database)
+			idempotencyKeyDomain = domains.NewIdempotencyKeyDomain(database)
+			txRunner = domains.NewTxRunner(database)
+		}
 	}
-	defer database.Close()
 
-	// 4. Initialize Domains (database interactions)
-	blogPostDomain := domains.NewBlogPostDomain(database)
+	blogPostDomain = m.BlogPostDomain(blogPostDomain)
+	txRunner = m.TxRunner(txRunner)
 
 	// 5. Initialize Handlers
-	blogPostHandlers := handlers.NewBlogPostHandler(blogPostDomain)
+	blogPostHandlers := handlers.NewBlogPostHandler(blogPostDomain,
+		handlers.WithTimeouts(cfg.Timeouts),
+		handlers.WithValidationRules(cfg.Validation),
+		handlers.WithMaxBodyBytes(cfg.MaxBodyBytes),
+		handlers.WithIdempotencyKeys(idempotencyKeyDomain, cfg.IdempotencyTTL),
+		handlers.WithTxRunner(txRunner),
+		handlers.WithMaxBatchOperations(cfg.MaxBatchOperations),
+	)
+
+	// 6. Start Background Workers
+	workers, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var wg sync.WaitGroup
+	purgeInterval := min(cfg.IdempotencyTTL, time.Hour)
+	purgeHeartbeat := handlers.NewHeartbeat(purgeInterval)
+	health.Register("idempotency_key_purge", purgeHeartbeat.Check)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer purgeHeartbeat.Stop()
+		purgeIdempotencyKeys(workers, idempotencyKeyDomain, purgeInterval, purgeHeartbeat)
+	}()
+	if replicas != nil {
+		replicaHeartbeat := handlers.NewHeartbeat(cfg.ReplicaCheckInterval + cfg.HealthCheckTimeout)
+		health.Register("replica_monitor", replicaHeartbeat.Check)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer replicaHeartbeat.Stop()
+			replicas.Monitor(workers, cfg.ReplicaCheckInterval, cfg.HealthCheckTimeout, replicaHeartbeat.Beat)
+		}()
+	}
+
+	// 7. Setup Router
+	separateMetrics := cfg.MetricsPort != "" && cfg.MetricsPort != cfg.ServerPort
+	routerOptions := []router.Option{
+		router.WithMetrics(m, !separateMetrics),
+		router.WithHealth(health),
+	}
+	if replicas != nil {
+		routerOptions = append(routerOptions, router.WithReadYourWrites(cfg.ReadYourWritesWindow))
+	}
+	if cfg.AdminToken != "" {
+		routerOptions = append(routerOptions, router.WithAdminToken(cfg.AdminToken))
+	} else {
+		slog.Warn("ADMIN_TOKEN is not set, the admin routes are not served")
+	}
+	r := router.SetupRoutes(blogPostHandlers, routerOptions...)
 
-	// 6. Setup Router
-	r := router.SetupRoutes(blogPostHandlers)
+	servers := []*http.Server{newServer(":"+cfg.ServerPort, r, cfg.ServerTimeouts)}
+	if separateMetrics {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", m.Handler())
+		servers = append(servers, newServer(":"+cfg.MetricsPort, mux, cfg.ServerTimeouts))
+	}
+
+	// 8. Start the Servers
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			slog.Info("Server listening", "addr", server.Addr)
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+
+	// 9. Shut Down on a Signal
+	exitCode := 0
+	select {
+	case <-signals.Done():
+		slog.Info("Shutting down")
+	case err := <-errs:
+		slog.Error("Server failed", "error", err)
+		exitCode = 1
+	}
+	// A second signal kills the process without waiting for the drain.
+	stopSignals()
+
+	health.ShutDown()
+	if exitCode == 0 {
+		time.Sleep(cfg.ServerTimeouts.ShutdownDelay)
+	}
+
+	ctx := context.Background()
+	if cfg.ServerTimeouts.Shutdown > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ServerTimeouts.Shutdown)
+		defer cancel()
+	}
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("Could not drain requests", "addr", server.Addr, "error", err)
+			exitCode = 1
+		}
+	}
+
+	stopWorkers()
+	wg.Wait()
+	slog.Info("Server stopped")
+	return exitCode
+}
+
+// newServer returns a server for handler on addr whose connections are
+// bounded by timeouts.
+func newServer(addr string, handler http.Handler, timeouts config.ServerTimeouts) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  timeouts.Read,
+		WriteTimeout: timeouts.Write,
+		IdleTimeout:  timeouts.Idle,
+	}
+}
 
-	// 7. Start the Server
-	serverAddr := ":" + cfg.ServerPort
-	log.Printf("Server listening on %s\n", serverAddr)
-	if err := r.Run(serverAddr); err != nil {
-		log.Fatalf("Server failed: %v", err)
+// purgeIdempotencyKeys deletes expired idempotency keys every interval until
+// ctx is done, beating heartbeat each time. Expired keys are already ignored;
+// this only reclaims space.
+func purgeIdempotencyKeys(ctx context.Context, keys domains.IdempotencyKeyDomain, interval time.Duration, heartbeat *handlers.Heartbeat) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			heartbeat.Beat()
+			if deleted, err := keys.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+				slog.ErrorContext(ctx, "Could not purge idempotency keys", "error", err)
+			} else if deleted > 0 {
+				slog.InfoContext(ctx, "Purged expired idempotency keys", "deleted", deleted)
+			}
+		}
 	}
-	
-	os.Exit(0)
 }
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+)
+
+type blogPostDomain struct {
+	next    domains.BlogPostDomain
+	metrics *Metrics
+}
+
+// BlogPostDomain returns a BlogPostDomain that records the duration and
+// errors of every call to next.
+func (m *Metrics) BlogPostDomain(next domains.BlogPostDomain) domains.BlogPostDomain {
+	return &blogPostDomain{next: next, metrics: m}
+}
+
+// observe records the operation once it returns. errp points at its named
+// error result.
+func (d *blogPostDomain) observe(operation string, start time.Time, errp *error) {
+	d.metrics.observeOperation(operation, start, *errp)
+}
+
+func (d *blogPostDomain) CreateBlogPost(ctx context.Context, blog *models.BlogPost) (ID *uuid.UUID, err error) {
+	defer d.observe("CreateBlogPost", time.Now(), &err)
+	return d.next.CreateBlogPost(ctx, blog)
+}
+
+func (d *blogPostDomain) GetBlogPost(ctx context.Context, ID *uuid.UUID) (blog *models.BlogPost, err error) {
+	defer d.observe("GetBlogPost", time.Now(), &err)
+	return d.next.GetBlogPost(ctx, ID)
+}
+
+func (d *blogPostDomain) GetBlogPostBySlug(ctx context.Context, slug string) (blog *models.BlogPost, err error) {
+	defer d.observe("GetBlogPostBySlug", time.Now(), &err)
+	return d.next.GetBlogPostBySlug(ctx, slug)
+}
+
+func (d *blogPostDomain) GetBlogPosts(ctx context.Context) (blogs []models.BlogPost, err error) {
+	defer d.observe("GetBlogPosts", time.Now(), &err)
+	return d.next.GetBlogPosts(ctx)
+}
+
+func (d *blogPostDomain) StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) (err error) {
+	defer d.observe("StreamBlogPosts", time.Now(), &err)
+	return d.next.StreamBlogPosts(ctx, fn)
+}
+
+func (d *blogPostDomain) UpdateBlogPost(ctx context.Context, blog *models.BlogPost) (err error) {
+	defer d.observe("UpdateBlogPost", time.Now(), &err)
+	return d.next.UpdateBlogPost(ctx, blog)
+}
+
+func (d *blogPostDomain) UpsertBlogPost(ctx context.Context, blog *models.BlogPost) (created bool, err error) {
+	defer d.observe("UpsertBlogPost", time.Now(), &err)
+	return d.next.UpsertBlogPost(ctx, blog)
+}
+
+func (d *blogPostDomain) DeleteBlogPost(ctx context.Context, ID *uuid.UUID) (err error) {
+	defer d.observe("DeleteBlogPost", time.Now(), &err)
+	return d.next.DeleteBlogPost(ctx, ID)
+}
+
+type txRunner struct {
+	next    domains.TxRunner
+	metrics *Metrics
+}
+
+// TxRunner returns a TxRunner that records each unit of work of next as the
+// operation WithTx, and the calls it makes to blog posts like BlogPostDomain.
+func (m *Metrics) TxRunner(next domains.TxRunner) domains.TxRunner {
+	return &txRunner{next: next, metrics: m}
+}
+
+func (r *txRunner) WithTx(ctx context.Context, fn func(tx domains.Repos) error) (err error) {
+	defer func(start time.Time) { r.metrics.observeOperation("WithTx", start, err) }(time.Now())
+	return r.next.WithTx(ctx, func(tx domains.Repos) error {
+		tx.BlogPosts = r.metrics.BlogPostDomain(tx.BlogPosts)
+		return fn(tx)
+	})
+}
@@ -0,0 +1,108 @@
+// Package metrics collects the Prometheus metrics of the server: HTTP
+// requests, database connection pool statistics and blog post domain
+// operations.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric of the server.
+const namespace = "blogassessment"
+
+// UnmatchedRoute is the route label of requests that matched no route, so
+// arbitrary paths don't each get a series of their own.
+const UnmatchedRoute = "unmatched"
+
+// Metrics holds the collectors of the server in a registry of their own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	domainDuration *prometheus.HistogramVec
+	domainErrors   *prometheus.CounterVec
+}
+
+// New returns Metrics with the HTTP and domain collectors, along with the Go
+// runtime and process ones, registered.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method, route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		domainDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "domain",
+			Name:      "operation_duration_seconds",
+			Help:      "Time taken by blog post domain operations, by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		domainErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "domain",
+			Name:      "operation_errors_total",
+			Help:      "Blog post domain operations that returned an error, by method and error code.",
+		}, []string{"operation", "code"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.domainDuration,
+		m.domainErrors,
+	)
+	return m
+}
+
+// RegisterDB adds the connection pool statistics of db, as reported by
+// sql.DB.Stats, labeled with name.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records an HTTP request handled in duration. route is the
+// template the request matched, such as /blog-post/:ID, or "" for none.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.requests.With(labels).Inc()
+	m.requestDuration.With(labels).Observe(duration.Seconds())
+}
+
+// observeOperation records a domain operation that started at start and
+// returned err.
+func (m *Metrics) observeOperation(operation string, start time.Time, err error) {
+	m.domainDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.domainErrors.WithLabelValues(operation, domains.ErrorCode(err)).Inc()
+	}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/db"
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/mock"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// scrape returns what the metrics handler serves.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	res := httptest.NewRecorder()
+	m.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("metrics handler returned %v", res.Code)
+	}
+	return res.Body.String()
+}
+
+func TestBlogPostDomain(t *testing.T) {
+	m := New()
+	memory := domains.NewMemoryBlogPostDomain()
+	domain := m.BlogPostDomain(memory)
+	runner := m.TxRunner(domains.NewMemoryTxRunner(memory, domains.NewMemoryIdempotencyKeyDomain()))
+	ctx := context.Background()
+
+	post := mock.MockBlogPost
+	if _, err := domain.CreateBlogPost(ctx, &post); err != nil {
+		t.Fatal(err)
+	}
+	missing := uuid.New()
+	if _, err := domain.GetBlogPost(ctx, &missing); err == nil {
+		t.Fatal("expected the post to be missing")
+	}
+	err := runner.WithTx(ctx, func(tx domains.Repos) error {
+		_, err := tx.BlogPosts.GetBlogPosts(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := scrape(t, m)
+	for _, operation := range []string{"CreateBlogPost", "GetBlogPost", "GetBlogPosts", "WithTx"} {
+		want := `blogassessment_domain_operation_duration_seconds_count{operation="` + operation + `"} 1`
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics are missing %q", want)
+		}
+	}
+	if strings.Contains(body, `operation="DeleteBlogPost"`) {
+		t.Error("metrics hold an operation that was not called")
+	}
+	if got := testutil.ToFloat64(m.domainErrors.WithLabelValues("GetBlogPost", "blog_post_not_found")); got != 1 {
+		t.Errorf("GetBlogPost counted %v errors, want 1", got)
+	}
+	if got := testutil.CollectAndCount(m.domainErrors); got != 1 {
+		t.Errorf("expected errors of one operation, got %d", got)
+	}
+}
+
+func TestObserveRequest(t *testing.T) {
+	m := New()
+	m.ObserveRequest(http.MethodGet, "/blog-post/:ID", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/blog-post/:ID", http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`blogassessment_http_requests_total{method="GET",route="/blog-post/:ID",status="200"} 2`,
+		`blogassessment_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`blogassessment_http_request_duration_seconds_sum{method="GET",route="/blog-post/:ID",status="200"} 0.05`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics are missing %q", want)
+		}
+	}
+}
+
+func TestRegisterDB(t *testing.T) {
+	database, err := db.ConnectDB(&config.Config{
+		Storage:    config.StorageSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "blog.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	m := New()
+	if err := m.RegisterDB(database, config.StorageSQLite); err != nil {
+		t.Fatal(err)
+	}
+	if body := scrape(t, m); !strings.Contains(body, `go_sql_max_open_connections{db_name="sqlite"}`) {
+		t.Errorf("metrics are missing the pool statistics:\n%s", body)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/db"
+)
+
+// runMigrate implements `blogassessment migrate up|down|status|force <version>`
+// using the migrations embedded in the binary.
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: blogassessment migrate up|down|status|force <version>")
+	}
+	flags.Parse(args)
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	command := flags.Arg(0)
+	forceVersion := 0
+	switch command {
+	case "up", "down", "status":
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return 2
+		}
+	case "force":
+		version, err := strconv.Atoi(flags.Arg(1))
+		if flags.NArg() != 2 || err != nil {
+			flags.Usage()
+			return 2
+		}
+		forceVersion = version
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Failed to load config: %v", err)
+		return 1
+	}
+	if cfg.Storage == config.StorageMemory {
+		log.Printf("migrate needs a database, STORAGE is %q", cfg.Storage)
+		return 1
+	}
+	database, err := db.ConnectDB(cfg)
+	if err != nil {
+		log.Printf("Could not connect to database: %v", err)
+		return 1
+	}
+	defer database.Close()
+
+	migrator, err := db.NewMigrator(database, cfg.Storage)
+	if err != nil {
+		log.Printf("Could not load migrations: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "force":
+		err = migrator.Force(ctx, forceVersion)
+	}
+	if err != nil {
+		log.Printf("migrate %s failed: %v", command, err)
+		return 1
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		log.Printf("Could not read migration status: %v", err)
+		return 1
+	}
+	printMigrationStatus(migrator, status)
+	return 0
+}
+
+func printMigrationStatus(migrator *db.Migrator, status *db.MigrationStatus) {
+	fmt.Printf("version %d (latest %d)", status.Version, status.Latest)
+	if status.Dirty {
+		fmt.Print(", dirty")
+	}
+	fmt.Println()
+
+	for _, migration := range migrator.Migrations() {
+		state := "applied"
+		if migration.Version > status.Version {
+			state = "pending"
+		}
+		fmt.Fprintf(os.Stdout, "  %06d_%s\t%s\n", migration.Version, migration.Name, state)
+	}
+}
@@ -1,6 +1,9 @@
 package mock
 
 import (
+	"context"
+	"time"
+
 	"github.com/DurgeshKr2242/blogassessment/domains"
 	"github.com/DurgeshKr2242/blogassessment/models"
 	"github.com/google/uuid"
@@ -20,6 +23,9 @@ const (
 
 	// DBOperationErrorUpdateBlog ...
 	DBOperationErrorUpdateBlog
+
+	// DBTimeoutError ...
+	DBTimeoutError
 )
 
 // FakeService is a fake struct for domain Service.
@@ -29,13 +35,14 @@ type FakeService struct {
 
 var (
 	MockID       = uuid.MustParse("259c7e70-57b0-40d9-8fd1-20a7ed901fae")
+	MockTime     = time.Date(2025, 2, 7, 22, 1, 38, 640214000, time.UTC)
 	MockBlogPost = models.BlogPost{
 		ID:          &MockID,
 		Body:        "Some body for the blog",
 		Description: "Some description for the blog",
 		Title:       "Some title for blog",
-		CreatedAt:   "2025-02-07T22:01:38.640214Z",
-		UpdatedAt:   "2025-02-07T22:01:38.640214Z",
+		CreatedAt:   MockTime,
+		UpdatedAt:   MockTime,
 	}
 	MockBlogPosts = []models.BlogPost{
 		{
@@ -43,13 +50,13 @@ var (
 			Body:        "Some body for the blog",
 			Description: "Some description for the blog",
 			Title:       "Some title for blog",
-			CreatedAt:   "2025-02-07T22:01:38.640214Z",
-			UpdatedAt:   "2025-02-07T22:01:38.640214Z",
+			CreatedAt:   MockTime,
+			UpdatedAt:   MockTime,
 		},
 	}
 )
 
-func (s *FakeService) CreateBlogPost(blog *models.BlogPost) (*uuid.UUID, error) {
+func (s *FakeService) CreateBlogPost(ctx context.Context, blog *models.BlogPost) (*uuid.UUID, error) {
 	if s.Err == DBOperationError {
 		return nil, domains.ErrorCreateBlogPostFailed
 	}
@@ -57,7 +64,26 @@ func (s *FakeService) CreateBlogPost(blog *models.BlogPost) (*uuid.UUID, error)
 	return &MockID, nil
 }
 
-func (s *FakeService) GetBlogPost(ID *uuid.UUID) (*models.BlogPost, error) {
+func (s *FakeService) GetBlogPost(ctx context.Context, ID *uuid.UUID) (*models.BlogPost, error) {
+	if s.Err == DBOperationError {
+		return nil, domains.ErrorGetBlogPostFailed
+	}
+	if s.Err == DBNotFoundError {
+		return nil, domains.ErrorBlogPostNotFound
+	}
+	if s.Err == DBTimeoutError {
+		return nil, domains.ErrorTimeout
+	}
+	if *ID != MockID {
+		return nil, domains.ErrorBlogPostNotFound
+	}
+
+	// A copy, so handlers that modify the post don't leak into other tests.
+	post := MockBlogPost
+	return &post, nil
+}
+
+func (s *FakeService) GetBlogPostBySlug(ctx context.Context, slug string) (*models.BlogPost, error) {
 	if s.Err == DBOperationError {
 		return nil, domains.ErrorGetBlogPostFailed
 	}
@@ -68,26 +94,55 @@ func (s *FakeService) GetBlogPost(ID *uuid.UUID) (*models.BlogPost, error) {
 	return &MockBlogPost, nil
 }
 
-func (s *FakeService) GetBlogPosts() ([]models.BlogPost, error) {
+func (s *FakeService) GetBlogPosts(ctx context.Context) ([]models.BlogPost, error) {
 	if s.Err == DBOperationError {
 		return nil, domains.ErrorGetBlogPostsFailed
 	}
+	if s.Err == DBTimeoutError {
+		return nil, domains.ErrorTimeout
+	}
 
 	return MockBlogPosts, nil
 }
 
-func (s *FakeService) UpdateBlogPost(post *models.BlogPost) error {
+func (s *FakeService) StreamBlogPosts(ctx context.Context, fn func(blog *models.BlogPost) error) error {
+	blogs, err := s.GetBlogPosts(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		blog := blogs[i]
+		if err := fn(&blog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FakeService) UpdateBlogPost(ctx context.Context, post *models.BlogPost) error {
 	if s.Err == DBOperationErrorUpdateBlog {
 		return domains.ErrorUpdateBlogPostFailed
 	}
 
 	post.ID = &MockID
-	post.CreatedAt = "2025-02-07T22:01:38.640214Z"
-	post.UpdatedAt = "2025-02-07T22:01:38.640214Z"
+	post.CreatedAt = MockTime
+	post.UpdatedAt = MockTime
 	return nil
 }
 
-func (s *FakeService) DeleteBlogPost(ID *uuid.UUID) error {
+func (s *FakeService) UpsertBlogPost(ctx context.Context, blog *models.BlogPost) (bool, error) {
+	if s.Err == DBOperationError {
+		return false, domains.ErrorUpsertBlogPostFailed
+	}
+	if s.Err == DBTimeoutError {
+		return false, domains.ErrorTimeout
+	}
+
+	blog.CreatedAt, blog.UpdatedAt = MockTime, MockTime
+	return *blog.ID != MockID, nil
+}
+
+func (s *FakeService) DeleteBlogPost(ctx context.Context, ID *uuid.UUID) error {
 	if s.Err == DBOperationError {
 		return domains.ErrorDeleteBlogPostFailed
 	}
@@ -96,3 +151,13 @@ func (s *FakeService) DeleteBlogPost(ID *uuid.UUID) error {
 	}
 	return nil
 }
+
+// FakeTxRunner runs units of work on Repos as they are, without a
+// transaction.
+type FakeTxRunner struct {
+	Repos domains.Repos
+}
+
+func (r *FakeTxRunner) WithTx(ctx context.Context, fn func(tx domains.Repos) error) error {
+	return fn(r.Repos)
+}
@@ -0,0 +1,35 @@
+package models
+
+import "encoding/json"
+
+// Modes of a BatchRequest.
+const (
+	// BatchAtomic applies every operation or, when one fails, none.
+	BatchAtomic = "atomic"
+	// BatchBestEffort applies each operation on its own and reports them
+	// one by one.
+	BatchBestEffort = "best_effort"
+)
+
+// Ops of a BatchOperation.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchRequest runs several blog post operations in one request. Mode
+// defaults to BatchAtomic.
+type BatchRequest struct {
+	Mode       string           `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1"`
+}
+
+// BatchOperation is one operation of a BatchRequest. Post holds a
+// CreateBlogPostRequest for create and an UpdateBlogPostRequest for update,
+// and is decoded once the operation itself is valid.
+type BatchOperation struct {
+	Op   string          `json:"op" binding:"required,oneof=create update delete"`
+	ID   string          `json:"id" binding:"required_unless=Op create,omitempty,uuid"`
+	Post json.RawMessage `json:"post" binding:"required_unless=Op delete,excluded_if=Op delete"`
+}
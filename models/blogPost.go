@@ -1,27 +1,70 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
-// BlogPost represents a blog post.
+// BlogPost represents a blog post. Timestamps are in UTC unless the post was
+// converted with In, and serialize as RFC 3339.
 type BlogPost struct {
 	ID          *uuid.UUID `json:"id"`
 	Title       string     `json:"title" binding:"required"`
 	Description string     `json:"description" binding:"required"`
 	Body        string     `json:"body" binding:"required"`
-	CreatedAt   string     `json:"created_at"`
-	UpdatedAt   string     `json:"updated_at"`
+	Slug        string     `json:"slug,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Draft       bool       `json:"draft,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// In returns a copy of the post with its timestamps in loc.
+func (b BlogPost) In(loc *time.Location) BlogPost {
+	b.CreatedAt = b.CreatedAt.In(loc)
+	b.UpdatedAt = b.UpdatedAt.In(loc)
+	return b
+}
+
+// EditableBlogPost is the document PATCH requests with a merge patch or a
+// JSON patch apply to: the fields of a blog post a client may change.
+type EditableBlogPost struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Body        string   `json:"body"`
+	Slug        string   `json:"slug,omitempty" binding:"max=255,slug"`
+	Tags        []string `json:"tags,omitempty"`
+	Draft       bool     `json:"draft,omitempty"`
+}
+
+// Editable returns the fields of the post a client may change.
+func (b BlogPost) Editable() EditableBlogPost {
+	return EditableBlogPost{
+		Title:       b.Title,
+		Description: b.Description,
+		Body:        b.Body,
+		Slug:        b.Slug,
+		Tags:        b.Tags,
+		Draft:       b.Draft,
+	}
 }
 
+// UpdateBlogPostRequest changes the fields that are present. Their lengths
+// are checked against config.ValidationRules when the request is bound. An
+// empty slug or tag list removes it.
 type UpdateBlogPostRequest struct {
-	Title       *string `json:"title,omitempty" binding:"omitempty,min=5,max=60"`
-	Description *string `json:"description,omitempty" binding:"omitempty,min=10,max=300"`
-	Body        *string `json:"body,omitempty" binding:"omitempty,min=10"`
+	Title       *string   `json:"title,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Body        *string   `json:"body,omitempty"`
+	Slug        *string   `json:"slug,omitempty" binding:"omitnil,max=255,slug"`
+	Tags        *[]string `json:"tags,omitempty"`
 }
 
+// CreateBlogPostRequest creates a blog post. The lengths of its fields are
+// checked against config.ValidationRules when the request is bound.
 type CreateBlogPostRequest struct {
-	Title       string `json:"title" binding:"required,min=5,max=60"`
-	Description string `json:"description" binding:"required,min=10,max=300"`
-	Body        string `json:"body" binding:"required,min=10"`
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description" binding:"required"`
+	Body        string `json:"body" binding:"required"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey records the request an Idempotency-Key header was first
+// sent with and, once it completed, its response. Status is 0 while the
+// request is still being processed.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,338 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON values.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidOperation reports a JSON Patch operation that is malformed,
+	// such as an unknown op or a missing value.
+	ErrInvalidOperation = errors.New("invalid operation")
+	// ErrPathNotFound reports a path that does not exist in the document.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrTestFailed reports a test operation whose value did not match.
+	ErrTestFailed = errors.New("test failed")
+)
+
+// Error reports the operation of a JSON Patch that could not be applied.
+type Error struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Operation is one operation of a JSON Patch document. Value is nil when
+// the member is absent, and "null" when it is JSON null.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ParseOperations parses a JSON Patch document.
+func ParseOperations(data []byte) ([]Operation, error) {
+	var ops []Operation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Merge applies the merge patch mergePatch to doc: members of an object in
+// the patch replace those of doc, null members remove them, and any other
+// patch replaces doc as a whole.
+func Merge(doc, mergePatch []byte) ([]byte, error) {
+	var target, p any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mergePatch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, p any) any {
+	patchObject, ok := p.(map[string]any)
+	if !ok {
+		return p
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// Apply applies ops to doc in order. Either every operation applies or doc
+// is left as it was and the *Error of the first failing one is returned.
+func Apply(doc []byte, ops []Operation) ([]byte, error) {
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		var err error
+		if root, err = applyOperation(root, op); err != nil {
+			return nil, &Error{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyOperation(root any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := operationValue(op)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(root, path, value)
+		case "replace":
+			if root, _, err = remove(root, path); err != nil {
+				return nil, err
+			}
+			return add(root, path, value)
+		default:
+			current, err := get(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return root, nil
+		}
+
+	case "remove":
+		root, _, err = remove(root, path)
+		return root, err
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidOperation)
+			}
+			var value any
+			if root, value, err = remove(root, from); err != nil {
+				return nil, err
+			}
+			return add(root, path, value)
+		}
+		value, err := get(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, deepCopy(value))
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, op.Op)
+}
+
+func operationValue(op Operation) (any, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrInvalidOperation)
+	}
+	var value any
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOperation, err)
+	}
+	return value, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalidOperation, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(root any, path []string) (any, error) {
+	current := root
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			current = value
+		case []any:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = node[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return current, nil
+}
+
+// add sets the value at path, inserting into arrays, and returns the new
+// root. "-" as the last token appends to an array.
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return root, nil
+	case []any:
+		i := len(node)
+		if last != "-" {
+			if i, err = arrayIndex(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return set(root, path[:len(path)-1], node)
+	}
+	return nil, ErrPathNotFound
+}
+
+// remove deletes the value at path and returns the new root and the value.
+func remove(root any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, root, nil
+	}
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[last]
+		if !ok {
+			return nil, nil, ErrPathNotFound
+		}
+		delete(node, last)
+		return root, value, nil
+	case []any:
+		i, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		root, err = set(root, path[:len(path)-1], node)
+		return root, value, err
+	}
+	return nil, nil, ErrPathNotFound
+}
+
+// set replaces the value at an existing path, needed when an array grows
+// or shrinks and its slice header changes.
+func set(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+	case []any:
+		i, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = value
+	}
+	return root, nil
+}
+
+// arrayIndex parses an array index token no greater than max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, ErrPathNotFound
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > max {
+		return 0, ErrPathNotFound
+	}
+	return i, nil
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, elem := range v {
+			c[key] = deepCopy(elem)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, elem := range v {
+			c[i] = deepCopy(elem)
+		}
+		return c
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func jsonEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	// Cases from RFC 7396, appendix A.
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		got, err := Merge([]byte(tc.doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("Merge(%s, %s): %v", tc.doc, tc.patch, err)
+		}
+		jsonEqual(t, got, tc.want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	// Mostly cases from RFC 6902, appendix A.
+	cases := map[string]struct {
+		doc, patch, want string
+		err              error
+	}{
+		"add member":         {`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, nil},
+		"add array element":  {`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+		"append":             {`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`, nil},
+		"add null":           {`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"foo":"bar","baz":null}`, nil},
+		"remove member":      {`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+		"remove element":     {`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, nil},
+		"replace":            {`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, nil},
+		"move member":        {`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+		"move element":       {`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, nil},
+		"copy":               {`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`, nil},
+		"test":               {`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`, nil},
+		"escaped pointer":    {`{"a/b":{"m~n":1}}`, `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`, `{"a/b":{"m~n":2}}`, nil},
+		"ignores extras":     {`{}`, `[{"op":"add","path":"/a","value":1,"extra":true}]`, `{"a":1}`, nil},
+		"test fails":         {`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", ErrTestFailed},
+		"missing parent":     {`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "", ErrPathNotFound},
+		"remove missing":     {`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, "", ErrPathNotFound},
+		"index out of range": {`{"foo":["a"]}`, `[{"op":"add","path":"/foo/2","value":"b"}]`, "", ErrPathNotFound},
+		"leading zero":       {`{"foo":["a","b"]}`, `[{"op":"remove","path":"/foo/01"}]`, "", ErrPathNotFound},
+		"missing value":      {`{}`, `[{"op":"add","path":"/a"}]`, "", ErrInvalidOperation},
+		"unknown op":         {`{}`, `[{"op":"frob","path":"/a"}]`, "", ErrInvalidOperation},
+		"move into child":    {`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, "", ErrInvalidOperation},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ops, err := ParseOperations([]byte(tc.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Apply([]byte(tc.doc), ops)
+			if tc.err != nil {
+				var patchErr *Error
+				if !errors.Is(err, tc.err) || !errors.As(err, &patchErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			jsonEqual(t, got, tc.want)
+		})
+	}
+}
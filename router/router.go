@@ -1,27 +1,105 @@
 package router
 
 import (
+	"io"
+	"time"
+
 	"github.com/DurgeshKr2242/blogassessment/handlers"
+	"github.com/DurgeshKr2242/blogassessment/metrics"
 	"github.com/gin-gonic/gin"
 )
 
+type options struct {
+	metrics      *metrics.Metrics
+	serveMetrics bool
+	health       *handlers.Health
+	readWindow   time.Duration
+	adminToken   string
+}
+
+// Option configures the routes set up by SetupRoutes.
+type Option func(*options)
+
+// WithMetrics records every request in m. GET /metrics serves m as well
+// when serve is true; otherwise it is left to a listener of its own.
+func WithMetrics(m *metrics.Metrics, serve bool) Option {
+	return func(o *options) {
+		o.metrics = m
+		o.serveMetrics = serve
+	}
+}
+
+// WithHealth answers the health probes with health, so readiness reflects
+// its checks and fails when the server shuts down.
+func WithHealth(health *handlers.Health) Option {
+	return func(o *options) {
+		o.health = health
+	}
+}
+
+// WithReadYourWrites makes clients read from the primary database for
+// window after they write, for setups with read replicas.
+func WithReadYourWrites(window time.Duration) Option {
+	return func(o *options) {
+		o.readWindow = window
+	}
+}
+
+// WithAdminToken serves the /admin routes to requests sending token as a
+// bearer token. Without it they are not served at all.
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
+
 // SetupRoutes configures all the routes for the application
-func SetupRoutes(blogPostHandler *handlers.BlogPostHandler) *gin.Engine {
-	r := gin.Default()
+func SetupRoutes(blogPostHandler *handlers.BlogPostHandler, opts ...Option) *gin.Engine {
+	o := options{health: handlers.NewHealth(handlers.DefaultCheckTimeout)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r := gin.New()
+	r.Use(handlers.RequestID, handlers.Tracing, handlers.AccessLog)
+	if o.metrics != nil {
+		r.Use(handlers.HTTPMetrics(o.metrics))
+	}
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, handlers.Recover))
+	if o.readWindow > 0 {
+		r.Use(handlers.ReadYourWrites(o.readWindow))
+	}
+
+	// Health checks. /health predates the split and reports readiness.
+	r.GET("/livez", o.health.Live)
+	r.GET("/readyz", o.health.Ready)
+	r.GET("/health", o.health.Ready)
 
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "OK"})
-	})
+	if o.metrics != nil && o.serveMetrics {
+		r.GET("/metrics", gin.WrapH(o.metrics.Handler()))
+	}
 
 	// Blog Post routes
 	blogRoutes := r.Group("/blog-post")
 	{
-		blogRoutes.POST("/", blogPostHandler.CreateBlogPost)
+		blogRoutes.POST("/", blogPostHandler.Idempotency, blogPostHandler.CreateBlogPost)
+		blogRoutes.POST("/batch", blogPostHandler.Idempotency, blogPostHandler.BatchBlogPosts)
 		blogRoutes.GET("/", blogPostHandler.GetBlogPosts)
 		blogRoutes.GET("/:ID", blogPostHandler.GetBlogPost)
 		blogRoutes.DELETE("/:ID", blogPostHandler.DeleteBlogPost)
 		blogRoutes.PATCH("/:ID", blogPostHandler.UpdateBlogPost)
+		blogRoutes.PUT("/:ID", blogPostHandler.ReplaceBlogPost)
+	}
+
+	// Metadata routes
+	r.GET("/meta/validation", blogPostHandler.GetValidationRules)
+
+	// Admin routes
+	if o.adminToken != "" {
+		adminRoutes := r.Group("/admin", handlers.AdminAuth(o.adminToken))
+		{
+			adminRoutes.GET("/export", blogPostHandler.ExportBlogPosts)
+		}
 	}
 
 	return r
@@ -0,0 +1,53 @@
+// Package tracing sets up OpenTelemetry tracing for the server.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName identifies the server in the spans it exports.
+const ServiceName = "blogassessment"
+
+// Setup installs the global tracer provider exporting spans as cfg
+// configures, and W3C trace context propagation. The returned function
+// flushes spans not yet exported and must be called before exiting.
+// With config.TraceExporterNone spans are not recorded, but trace context
+// still passes through.
+func Setup(ctx context.Context, cfg *config.Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.TraceExporter {
+	case config.TraceExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TraceExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case config.TraceExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.TraceExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s trace exporter: %w", cfg.TraceExporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
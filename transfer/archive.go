@@ -0,0 +1,170 @@
+package transfer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+const (
+	archiveManifest = "manifest.json"
+	archivePostsDir = "posts"
+)
+
+// ArchiveWriter writes an export as a tar.gz of Markdown files with a
+// manifest.json holding the export header.
+type ArchiveWriter struct {
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+// NewArchiveWriter returns a writer that writes the manifest to w first.
+func NewArchiveWriter(w io.Writer, header Header) (*ArchiveWriter, error) {
+	gz := gzip.NewWriter(w)
+	writer := &ArchiveWriter{gz: gz, tw: tar.NewWriter(gz), modTime: header.ExportedAt}
+
+	manifest, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.writeFile(archiveManifest, manifest); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WritePost adds a post to the archive as posts/<slug>.md.
+func (w *ArchiveWriter) WritePost(post *models.BlogPost) error {
+	content, err := FormatMarkdown(post)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(path.Join(archivePostsDir, markdownFileName(post)), content)
+}
+
+// Close flushes the archive. It does not close the underlying writer.
+func (w *ArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+func (w *ArchiveWriter) writeFile(name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: w.modTime,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(content)
+	return err
+}
+
+// ReadArchive reads the posts of a tar.gz Markdown archive, which must hold
+// a valid manifest. Files that cannot be parsed are reported in the returned
+// map, keyed by their name.
+func ReadArchive(r io.Reader, source string) ([]Item, map[string]error, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", source, err)
+	}
+	defer gz.Close()
+
+	items := []Item{}
+	errs := map[string]error{}
+	manifest := false
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", source, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := source + ":" + hdr.Name
+		switch {
+		case path.Clean(hdr.Name) == archiveManifest:
+			var header Header
+			if err := json.NewDecoder(tr).Decode(&header); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if err := header.Validate(); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", name, err)
+			}
+			manifest = true
+
+		case isMarkdownFile(hdr.Name):
+			post, err := ParseMarkdown(tr)
+			if err != nil {
+				errs[name] = err
+				continue
+			}
+			if post.Slug == "" && post.ID == nil {
+				post.Slug = slugFromPath(hdr.Name)
+			}
+			items = append(items, Item{Source: name, Post: *post})
+		}
+	}
+	if !manifest {
+		return nil, nil, fmt.Errorf("%s: %w: missing %s", source, ErrorUnsupportedFormat, archiveManifest)
+	}
+	return items, errs, nil
+}
+
+// Load reads posts from a directory of Markdown files, a single Markdown
+// file, a tar.gz archive or an NDJSON export, depending on what path is.
+func Load(p string) ([]Item, map[string]error, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return LoadMarkdownDir(p)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	lower := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ReadArchive(f, p)
+
+	case strings.HasSuffix(lower, ".ndjson"), strings.HasSuffix(lower, ".jsonl"):
+		items, err := ReadNDJSON(f, p)
+		return items, map[string]error{}, err
+
+	case isMarkdownFile(p):
+		post, err := ParseMarkdown(f)
+		if err != nil {
+			return nil, map[string]error{p: err}, nil
+		}
+		if post.Slug == "" && post.ID == nil {
+			post.Slug = slugFromPath(p)
+		}
+		return []Item{{Source: p, Post: *post}}, map[string]error{}, nil
+	}
+	return nil, nil, fmt.Errorf("%s: %w", p, ErrorUnsupportedFormat)
+}
@@ -0,0 +1,159 @@
+package transfer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+)
+
+var (
+	exportID    = uuid.MustParse("259c7e70-57b0-40d9-8fd1-20a7ed901fae")
+	exportPosts = []models.BlogPost{
+		{
+			ID:          &exportID,
+			Title:       "Some title for blog",
+			Description: "Some description for the blog",
+			Body:        "Some body for the blog\n\nWith a second paragraph.",
+			Slug:        "some-title",
+			Tags:        []string{"go", "blog"},
+			Draft:       true,
+			CreatedAt:   mustTime("2025-02-07T22:01:38.640214Z"),
+			UpdatedAt:   mustTime("2025-02-08T10:00:00Z"),
+		},
+		{
+			ID:          &exportID,
+			Title:       "Post without slug",
+			Description: "Description",
+			Body:        "Body",
+			CreatedAt:   mustTime("2025-02-07T22:01:38.640214Z"),
+			UpdatedAt:   mustTime("2025-02-07T22:01:38.640214Z"),
+		},
+	}
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewArchiveWriter(&buf, NewHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range exportPosts {
+		if err := writer.WritePost(&exportPosts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	items, errs, err := ReadArchive(&buf, "export.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	assertRoundTrip(t, items)
+}
+
+func TestReadArchive_MissingManifest(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("---\ntitle: Title\n---\nBody\n")
+	if err := tw.WriteHeader(&tar.Header{Name: "posts/title.md", Mode: 0o644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ReadArchive(&buf, "export.tar.gz"); !errors.Is(err, ErrorUnsupportedFormat) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewNDJSONWriter(&buf, NewHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range exportPosts {
+		if err := writer.WritePost(&exportPosts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := ReadNDJSON(&buf, "export.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRoundTrip(t, items)
+}
+
+func TestReadNDJSON_UnsupportedVersion(t *testing.T) {
+	input := `{"kind":"header","header":{"format":"blogassessment","version":99}}` + "\n"
+	if _, err := ReadNDJSON(strings.NewReader(input), "export.ndjson"); !errors.Is(err, ErrorUnsupportedVersion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReadNDJSON_Trailer(t *testing.T) {
+	const (
+		v1      = `{"kind":"header","header":{"format":"blogassessment","version":1}}` + "\n"
+		v2      = `{"kind":"header","header":{"format":"blogassessment","version":2}}` + "\n"
+		post    = `{"kind":"post","post":{"title":"Title"}}` + "\n"
+		trailer = `{"kind":"trailer","trailer":{"posts":2}}` + "\n"
+	)
+	cases := map[string]struct {
+		input string
+		posts int
+		err   error
+	}{
+		"When the export is complete":            {input: v2 + post + post + trailer, posts: 2},
+		"When the trailer is missing":            {input: v2 + post + post, err: ErrorTruncatedExport},
+		"When posts are missing":                 {input: v2 + post + trailer, err: ErrorTruncatedExport},
+		"When a record follows the trailer":      {input: v2 + post + post + trailer + post, err: ErrorUnsupportedFormat},
+		"When a version 1 export has no trailer": {input: v1 + post, posts: 1},
+	}
+	for testName, tc := range cases {
+		t.Run(testName, func(t *testing.T) {
+			items, err := ReadNDJSON(strings.NewReader(tc.input), "export.ndjson")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(items) != tc.posts {
+				t.Errorf("read %d posts, want %d", len(items), tc.posts)
+			}
+		})
+	}
+}
+
+func assertRoundTrip(t *testing.T, items []Item) {
+	t.Helper()
+	if len(items) != len(exportPosts) {
+		t.Fatalf("unexpected number of posts: got %d want %d", len(items), len(exportPosts))
+	}
+	for i, item := range items {
+		if !reflect.DeepEqual(item.Post, exportPosts[i]) {
+			t.Errorf("post %d did not round trip:\ngot  %+v\nwant %+v\n", i, item.Post, exportPosts[i])
+		}
+	}
+}
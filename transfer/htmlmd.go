@@ -0,0 +1,253 @@
+package transfer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	blankLines       = regexp.MustCompile(`\n[ \t]*\n`)
+	extraNewlines    = regexp.MustCompile(`\n{3,}`)
+	trailingSpaces   = regexp.MustCompile(`[ \t]+\n`)
+	captionShortcode = regexp.MustCompile(`\[/?caption[^\]]*\]`)
+	whitespaceRun    = regexp.MustCompile(`\s+`)
+	paragraphTag     = regexp.MustCompile(`(?i)<p[\s>]`)
+)
+
+// HTMLToMarkdown converts WordPress post HTML to Markdown. Elements without a
+// Markdown equivalent, such as tables and embeds, are kept as raw HTML.
+func HTMLToMarkdown(src string) string {
+	src = captionShortcode.ReplaceAllString(src, "")
+	src = autoParagraph(src)
+
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+
+	c := &mdConverter{}
+	for _, n := range nodes {
+		c.node(n)
+	}
+	return normalizeMarkdown(c.String())
+}
+
+// autoParagraph wraps blank-line separated text in <p> tags, like WordPress
+// does when rendering classic editor content that was saved without them.
+func autoParagraph(src string) string {
+	if paragraphTag.MatchString(src) {
+		return src
+	}
+	var b strings.Builder
+	for _, para := range blankLines.Split(strings.ReplaceAll(src, "\r\n", "\n"), -1) {
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(strings.TrimSpace(para), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+func normalizeMarkdown(md string) string {
+	md = trailingSpaces.ReplaceAllStringFunc(md, func(s string) string {
+		// Keep Markdown hard line breaks.
+		if s == "  \n" {
+			return s
+		}
+		return "\n"
+	})
+	md = extraNewlines.ReplaceAllString(md, "\n\n")
+	return strings.TrimSpace(md)
+}
+
+type mdConverter struct {
+	strings.Builder
+	listDepth int
+}
+
+func (c *mdConverter) atLineStart() bool {
+	s := c.String()
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+func (c *mdConverter) block() {
+	if c.Len() > 0 {
+		c.WriteString("\n\n")
+	}
+}
+
+func (c *mdConverter) text(s string) {
+	s = whitespaceRun.ReplaceAllString(s, " ")
+	if c.atLineStart() || strings.HasSuffix(c.String(), " ") {
+		s = strings.TrimLeft(s, " ")
+	}
+	c.WriteString(s)
+}
+
+func (c *mdConverter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+// inline renders the children of n to a string without touching c.
+func (c *mdConverter) inline(n *html.Node) string {
+	sub := &mdConverter{listDepth: c.listDepth}
+	sub.children(n)
+	return strings.TrimSpace(sub.String())
+}
+
+func (c *mdConverter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure, atom.Figcaption, atom.Header, atom.Footer:
+		c.block()
+		c.children(n)
+		c.block()
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		c.block()
+		c.WriteString(strings.Repeat("#", level) + " " + c.inline(n))
+		c.block()
+
+	case atom.Br:
+		c.WriteString("  \n")
+
+	case atom.Strong, atom.B:
+		c.wrap(n, "**")
+
+	case atom.Em, atom.I:
+		c.wrap(n, "_")
+
+	case atom.Del, atom.S, atom.Strike:
+		c.wrap(n, "~~")
+
+	case atom.Code:
+		c.WriteString("`" + textContent(n) + "`")
+
+	case atom.Pre:
+		c.block()
+		c.WriteString("```\n" + strings.Trim(textContent(n), "\n") + "\n```")
+		c.block()
+
+	case atom.A:
+		inner := c.inline(n)
+		if href := attr(n, "href"); href != "" {
+			c.WriteString("[" + inner + "](" + href + ")")
+		} else {
+			c.WriteString(inner)
+		}
+
+	case atom.Img:
+		c.WriteString("![" + attr(n, "alt") + "](" + attr(n, "src") + ")")
+
+	case atom.Ul, atom.Ol:
+		c.list(n)
+
+	case atom.Blockquote:
+		sub := &mdConverter{}
+		sub.children(n)
+		c.block()
+		for i, line := range strings.Split(normalizeMarkdown(sub.String()), "\n") {
+			if i > 0 {
+				c.WriteString("\n")
+			}
+			c.WriteString(strings.TrimRight("> "+line, " "))
+		}
+		c.block()
+
+	case atom.Hr:
+		c.block()
+		c.WriteString("---")
+		c.block()
+
+	case atom.Script, atom.Style:
+
+	case atom.Table, atom.Iframe, atom.Video, atom.Audio, atom.Object, atom.Embed:
+		var raw strings.Builder
+		if err := html.Render(&raw, n); err == nil {
+			c.block()
+			c.WriteString(raw.String())
+			c.block()
+		}
+
+	default:
+		c.children(n)
+	}
+}
+
+func (c *mdConverter) wrap(n *html.Node, marker string) {
+	if inner := c.inline(n); inner != "" {
+		c.WriteString(marker + inner + marker)
+	}
+}
+
+func (c *mdConverter) list(n *html.Node) {
+	indent := strings.Repeat("  ", c.listDepth)
+	if c.listDepth == 0 {
+		c.block()
+	} else if !c.atLineStart() {
+		c.WriteString("\n")
+	}
+
+	number := 0
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		number++
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+		}
+
+		sub := &mdConverter{listDepth: c.listDepth + 1}
+		sub.children(li)
+		item := strings.TrimSpace(extraNewlines.ReplaceAllString(sub.String(), "\n"))
+		item = strings.ReplaceAll(item, "\n\n", "\n")
+
+		if !c.atLineStart() {
+			c.WriteString("\n")
+		}
+		c.WriteString(indent + marker + item + "\n")
+	}
+
+	if c.listDepth == 0 {
+		c.block()
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
@@ -0,0 +1,257 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/DurgeshKr2242/blogassessment/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+// Action describes what an import did, or would do in dry-run mode, with an item.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionSkipped   Action = "skipped"
+	ActionFailed    Action = "failed"
+)
+
+var (
+	ErrorMissingKey  = errors.New("post has neither a slug nor an id")
+	ErrorInvalidPost = errors.New("invalid post")
+)
+
+// Result is the outcome of importing a single item.
+type Result struct {
+	Source  string
+	Slug    string
+	Action  Action
+	Changes []string
+	Err     error
+}
+
+// Summary collects the results of an import.
+type Summary struct {
+	DryRun  bool
+	Results []Result
+}
+
+// Count returns the number of results with the given action.
+func (s *Summary) Count(action Action) int {
+	n := 0
+	for _, r := range s.Results {
+		if r.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes a human readable diff summary of the import to w.
+func (s *Summary) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range s.Results {
+		detail := strings.Join(r.Changes, ", ")
+		if r.Err != nil {
+			detail = r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Action, r.Slug, r.Source, detail)
+	}
+	tw.Flush()
+
+	prefix := ""
+	if s.DryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(w, "%s%d created, %d updated, %d unchanged, %d skipped, %d failed\n", prefix,
+		s.Count(ActionCreate), s.Count(ActionUpdate), s.Count(ActionUnchanged), s.Count(ActionSkipped), s.Count(ActionFailed))
+}
+
+// AddFailures records items that could not be read from their source.
+func (s *Summary) AddFailures(errs map[string]error) {
+	sources := make([]string, 0, len(errs))
+	for source := range errs {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	for _, source := range sources {
+		s.Results = append(s.Results, Result{Source: source, Action: ActionFailed, Err: errs[source]})
+	}
+}
+
+// AddSkipped records items that were left out of the import on purpose.
+func (s *Summary) AddSkipped(results []Result) {
+	s.Results = append(s.Results, results...)
+}
+
+// Importer writes posts through a BlogPostDomain. Imports are keyed on the
+// post slug, or on its id for posts without one, so running the same import
+// twice leaves the posts unchanged.
+type Importer struct {
+	domain    domains.BlogPostDomain
+	validator *validator.Validate
+	dryRun    bool
+	progress  func(done, total int, result Result)
+}
+
+// NewImporter returns an Importer that fails the posts breaking rules, as
+// the API would reject them. In dry-run mode nothing is written.
+func NewImporter(domain domains.BlogPostDomain, rules config.ValidationRules, dryRun bool) *Importer {
+	return &Importer{domain: domain, validator: validation.NewValidator(rules), dryRun: dryRun}
+}
+
+// OnProgress registers fn to be called after each item has been imported.
+func (i *Importer) OnProgress(fn func(done, total int, result Result)) *Importer {
+	i.progress = fn
+	return i
+}
+
+// Import creates or updates every item and reports what happened to each.
+func (i *Importer) Import(ctx context.Context, items []Item) *Summary {
+	summary := &Summary{DryRun: i.dryRun}
+	seen := map[string]string{}
+
+	for n, item := range items {
+		result := Result{Source: item.Source, Slug: item.Post.Slug}
+		key := importKey(&item.Post)
+		if first, ok := seen[key]; ok && key != "" {
+			result.Action = ActionFailed
+			result.Err = fmt.Errorf("duplicate post, already imported from %s", first)
+		} else {
+			seen[key] = item.Source
+			i.importItem(ctx, item, &result)
+		}
+		summary.Results = append(summary.Results, result)
+		if i.progress != nil {
+			i.progress(n+1, len(items), result)
+		}
+	}
+	return summary
+}
+
+func (i *Importer) importItem(ctx context.Context, item Item, result *Result) {
+	post := item.Post
+	// Postgres stores microseconds, so compare imported timestamps at that
+	// precision.
+	post.CreatedAt = post.CreatedAt.Round(time.Microsecond)
+	post.UpdatedAt = post.UpdatedAt.Round(time.Microsecond)
+	if err := i.validate(&post); err != nil {
+		result.Action, result.Err = ActionFailed, err
+		return
+	}
+
+	var existing *models.BlogPost
+	var err error
+	switch {
+	case post.Slug != "":
+		existing, err = i.domain.GetBlogPostBySlug(ctx, post.Slug)
+	case post.ID != nil:
+		result.Slug = post.ID.String()
+		existing, err = i.domain.GetBlogPost(ctx, post.ID)
+	default:
+		result.Action, result.Err = ActionFailed, ErrorMissingKey
+		return
+	}
+	if err != nil && !errors.Is(err, domains.ErrorBlogPostNotFound) {
+		result.Action, result.Err = ActionFailed, err
+		return
+	}
+
+	if existing == nil {
+		result.Action = ActionCreate
+		if !i.dryRun {
+			if _, err := i.domain.CreateBlogPost(ctx, &post); err != nil {
+				result.Action, result.Err = ActionFailed, err
+			}
+		}
+		return
+	}
+
+	// A post matched by its id keeps its slug, as the export it comes from
+	// may not carry slugs.
+	if post.Slug == "" {
+		post.Slug = existing.Slug
+	}
+	result.Changes = diffPosts(existing, &post)
+	if len(result.Changes) == 0 {
+		result.Action = ActionUnchanged
+		return
+	}
+
+	result.Action = ActionUpdate
+	if !i.dryRun {
+		// Upserting keeps the imported timestamps, where updating would
+		// set updated_at to now.
+		post.ID = existing.ID
+		if _, err := i.domain.UpsertBlogPost(ctx, &post); err != nil {
+			result.Action, result.Err = ActionFailed, err
+		}
+	}
+}
+
+// validate checks post with the validator of create requests.
+func (i *Importer) validate(post *models.BlogPost) error {
+	err := i.validator.Struct(models.CreateBlogPostRequest{
+		Title:       post.Title,
+		Description: post.Description,
+		Body:        post.Body,
+	})
+	if err == nil {
+		return nil
+	}
+	var problems []string
+	for _, e := range validation.CustomValidationError(err) {
+		problems = append(problems, e.Field+" "+e.Message)
+	}
+	return fmt.Errorf("%w: %s", ErrorInvalidPost, strings.Join(problems, ", "))
+}
+
+func importKey(post *models.BlogPost) string {
+	if post.Slug != "" {
+		return "slug:" + post.Slug
+	}
+	if post.ID != nil {
+		return "id:" + post.ID.String()
+	}
+	return ""
+}
+
+// diffPosts lists the imported fields that differ between two posts.
+func diffPosts(a, b *models.BlogPost) []string {
+	var changes []string
+	if a.Title != b.Title {
+		changes = append(changes, "title")
+	}
+	if a.Description != b.Description {
+		changes = append(changes, "description")
+	}
+	if a.Body != b.Body {
+		changes = append(changes, "body")
+	}
+	if !slices.Equal(a.Tags, b.Tags) {
+		changes = append(changes, "tags")
+	}
+	if a.Draft != b.Draft {
+		changes = append(changes, "draft")
+	}
+	// Posts imported without timestamps keep the stored ones.
+	if !b.CreatedAt.IsZero() && !a.CreatedAt.Equal(b.CreatedAt) {
+		changes = append(changes, "created_at")
+	}
+	if !b.UpdatedAt.IsZero() && !a.UpdatedAt.Equal(b.UpdatedAt) {
+		changes = append(changes, "updated_at")
+	}
+	return changes
+}
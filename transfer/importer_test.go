@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/domains"
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+func TestImporter_Import(t *testing.T) {
+	ctx := context.Background()
+	domain := domains.NewMemoryBlogPostDomain()
+	rules := config.DefaultValidationRules()
+	items := []Item{
+		{Source: "a.md", Post: newPost("Post A", "a", mustTime("2020-01-01T00:00:00Z"))},
+		{Source: "b.md", Post: newPost("Post B", "b", time.Time{})},
+		{Source: "c.md", Post: newPost("Post C", "", time.Time{})},
+		{Source: "dup.md", Post: newPost("Post A again", "a", time.Time{})},
+		{Source: "short.md", Post: newPost("D", "d", time.Time{})},
+	}
+
+	dryRun := NewImporter(domain, rules, true).Import(ctx, items)
+	assertActions(t, dryRun, ActionCreate, ActionCreate, ActionFailed, ActionFailed, ActionFailed)
+	if err := dryRun.Results[4].Err; !errors.Is(err, ErrorInvalidPost) {
+		t.Errorf("a post breaking the validation rules failed with %v", err)
+	}
+	if blogs, _ := domain.GetBlogPosts(ctx); len(blogs) != 0 {
+		t.Fatalf("dry run wrote %d posts", len(blogs))
+	}
+
+	first := NewImporter(domain, rules, false).Import(ctx, items[:2])
+	assertActions(t, first, ActionCreate, ActionCreate)
+
+	post, err := domain.GetBlogPostBySlug(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !post.CreatedAt.Equal(mustTime("2020-01-01T00:00:00Z")) {
+		t.Errorf("original timestamp was not kept: %s", post.CreatedAt)
+	}
+
+	items[1].Post.Body = "Changed body"
+	second := NewImporter(domain, rules, false).Import(ctx, items[:2])
+	assertActions(t, second, ActionUnchanged, ActionUpdate)
+	if changes := second.Results[1].Changes; !reflect.DeepEqual(changes, []string{"body"}) {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+
+	items[0].Post.Body = "Changed body"
+	items[0].Post.UpdatedAt = mustTime("2021-01-01T00:00:00Z")
+	third := NewImporter(domain, rules, false).Import(ctx, items[:1])
+	assertActions(t, third, ActionUpdate)
+	if changes := third.Results[0].Changes; !reflect.DeepEqual(changes, []string{"body", "updated_at"}) {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	post, err = domain.GetBlogPostBySlug(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !post.CreatedAt.Equal(mustTime("2020-01-01T00:00:00Z")) || !post.UpdatedAt.Equal(mustTime("2021-01-01T00:00:00Z")) {
+		t.Errorf("imported timestamps were not kept on update: %s, %s", post.CreatedAt, post.UpdatedAt)
+	}
+
+	items[0].Post.UpdatedAt = mustTime("2022-01-01T00:00:00Z")
+	fourth := NewImporter(domain, rules, false).Import(ctx, items[:1])
+	assertActions(t, fourth, ActionUpdate)
+	if changes := fourth.Results[0].Changes; !reflect.DeepEqual(changes, []string{"updated_at"}) {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+
+	if blogs, _ := domain.GetBlogPosts(ctx); len(blogs) != 2 {
+		t.Errorf("import is not idempotent, %d posts stored", len(blogs))
+	}
+}
+
+func TestImporter_ImportByIDKeepsSlug(t *testing.T) {
+	ctx := context.Background()
+	domain := domains.NewMemoryBlogPostDomain()
+	stored := newPost("Post A", "a", time.Time{})
+	ID, err := domain.CreateBlogPost(ctx, &stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported := newPost("Post A", "", time.Time{})
+	imported.ID = ID
+	imported.Body = "Changed body"
+	summary := NewImporter(domain, config.DefaultValidationRules(), false).Import(ctx, []Item{{Source: "a.ndjson:2", Post: imported}})
+	assertActions(t, summary, ActionUpdate)
+
+	post, err := domain.GetBlogPost(ctx, ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Slug != "a" || post.Body != "Changed body" {
+		t.Errorf("import by id stored slug %q and body %q", post.Slug, post.Body)
+	}
+}
+
+func newPost(title, slug string, createdAt time.Time) models.BlogPost {
+	return models.BlogPost{
+		Title:       title,
+		Description: "Description of " + title,
+		Body:        "Body of " + title,
+		Slug:        slug,
+		CreatedAt:   createdAt,
+	}
+}
+
+func assertActions(t *testing.T, summary *Summary, actions ...Action) {
+	t.Helper()
+	got := []Action{}
+	for _, r := range summary.Results {
+		got = append(got, r.Action)
+	}
+	if !reflect.DeepEqual(got, actions) {
+		t.Errorf("unexpected actions:\ngot  %v\nwant %v\n", got, actions)
+	}
+}
+
+func mustTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,250 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelimiter = "---"
+
+var (
+	ErrorMissingFrontMatter = errors.New("missing front matter")
+	ErrorMissingTitle       = errors.New("front matter has no title")
+	ErrorInvalidDate        = errors.New("front matter has an invalid date")
+	ErrorInvalidID          = errors.New("front matter has an invalid id")
+
+	// dateLayouts are the date formats commonly emitted by static site generators.
+	dateLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// FrontMatter is the YAML header of a Markdown post.
+type FrontMatter struct {
+	ID          string   `yaml:"id,omitempty"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description,omitempty"`
+	Date        string   `yaml:"date,omitempty"`
+	Updated     string   `yaml:"updated,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Slug        string   `yaml:"slug,omitempty"`
+	Draft       bool     `yaml:"draft,omitempty"`
+}
+
+// Item is a post read from an import source.
+type Item struct {
+	Source string
+	Post   models.BlogPost
+}
+
+// ParseMarkdown reads a Markdown document with YAML front matter into a blog post.
+func ParseMarkdown(r io.Reader) (*models.BlogPost, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+
+	header, body, err := splitFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var fm FrontMatter
+	if err := yaml.Unmarshal(header, &fm); err != nil {
+		return nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	if strings.TrimSpace(fm.Title) == "" {
+		return nil, ErrorMissingTitle
+	}
+
+	post := &models.BlogPost{
+		Title:       fm.Title,
+		Description: fm.Description,
+		Body:        strings.TrimSpace(string(body)),
+		Slug:        fm.Slug,
+		Tags:        fm.Tags,
+		Draft:       fm.Draft,
+	}
+	if fm.ID != "" {
+		ID, err := uuid.Parse(fm.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrorInvalidID, fm.ID)
+		}
+		post.ID = &ID
+	}
+	if fm.Date != "" {
+		date, err := parseDate(fm.Date)
+		if err != nil {
+			return nil, err
+		}
+		post.CreatedAt = date.UTC()
+		post.UpdatedAt = post.CreatedAt
+	}
+	if fm.Updated != "" {
+		updated, err := parseDate(fm.Updated)
+		if err != nil {
+			return nil, err
+		}
+		post.UpdatedAt = updated.UTC()
+	}
+	return post, nil
+}
+
+// FormatMarkdown renders a post as Markdown with YAML front matter, in the
+// form ParseMarkdown reads back.
+func FormatMarkdown(post *models.BlogPost) ([]byte, error) {
+	fm := FrontMatter{
+		Title:       post.Title,
+		Description: post.Description,
+		Date:        formatDate(post.CreatedAt),
+		Updated:     formatDate(post.UpdatedAt),
+		Tags:        post.Tags,
+		Slug:        post.Slug,
+		Draft:       post.Draft,
+	}
+	if post.ID != nil {
+		fm.ID = post.ID.String()
+	}
+	if fm.Updated == fm.Date {
+		fm.Updated = ""
+	}
+
+	header, err := yaml.Marshal(&fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(header)
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(post.Body)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// markdownFileName names the file a post is exported to.
+func markdownFileName(post *models.BlogPost) string {
+	name := post.Slug
+	if name == "" && post.ID != nil {
+		name = post.ID.String()
+	}
+	if name == "" {
+		name = Slugify(post.Title)
+	}
+	return name + ".md"
+}
+
+// LoadMarkdownDir walks root and parses every Markdown file below it. Files
+// without a slug or id in their front matter get a slug derived from their
+// file name.
+// Files that cannot be parsed are reported in the returned map, keyed by path.
+func LoadMarkdownDir(root string) ([]Item, map[string]error, error) {
+	items := []Item{}
+	errs := map[string]error{}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isMarkdownFile(path) {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		post, err := ParseMarkdown(f)
+		if err != nil {
+			errs[path] = err
+			return nil
+		}
+		if post.Slug == "" && post.ID == nil {
+			post.Slug = slugFromPath(path)
+		}
+		items = append(items, Item{Source: path, Post: *post})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, errs, nil
+}
+
+func splitFrontMatter(content []byte) ([]byte, []byte, error) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterDelimiter {
+		return nil, nil, ErrorMissingFrontMatter
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == frontMatterDelimiter {
+			header := strings.Join(lines[1:i], "")
+			body := strings.Join(lines[i+1:], "")
+			return []byte(header), []byte(body), nil
+		}
+	}
+	return nil, nil, ErrorMissingFrontMatter
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrorInvalidDate, value)
+}
+
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// slugFromPath derives a slug from a file name, using the directory name for
+// page bundles such as "hello-world/index.md".
+func slugFromPath(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if strings.EqualFold(name, "index") || strings.EqualFold(name, "_index") {
+		name = filepath.Base(filepath.Dir(path))
+	}
+	return Slugify(name)
+}
+
+// Slugify lower-cases s and replaces runs of non alphanumeric characters with dashes.
+func Slugify(s string) string {
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
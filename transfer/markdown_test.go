@@ -0,0 +1,108 @@
+package transfer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		post  *models.BlogPost
+		err   error
+	}{
+		"When front matter has every field": {
+			input: "---\ntitle: Hello World\ndescription: A first post\ndate: 2021-03-04T05:06:07+02:00\ntags: [go, blog]\nslug: hello\ndraft: true\n---\n\nSome body\n",
+			post: &models.BlogPost{
+				Title:       "Hello World",
+				Description: "A first post",
+				Body:        "Some body",
+				Slug:        "hello",
+				Tags:        []string{"go", "blog"},
+				Draft:       true,
+				CreatedAt:   mustTime("2021-03-04T03:06:07Z"),
+				UpdatedAt:   mustTime("2021-03-04T03:06:07Z"),
+			},
+		},
+		"When date has no time and lines end with CRLF": {
+			input: "---\r\ntitle: Dated\r\ndate: 2020-01-02\r\n---\r\nBody\r\n",
+			post: &models.BlogPost{
+				Title:     "Dated",
+				Body:      "Body",
+				CreatedAt: mustTime("2020-01-02T00:00:00Z"),
+				UpdatedAt: mustTime("2020-01-02T00:00:00Z"),
+			},
+		},
+		"When front matter is missing": {
+			input: "# Just markdown\n",
+			err:   ErrorMissingFrontMatter,
+		},
+		"When front matter is not closed": {
+			input: "---\ntitle: Open\n",
+			err:   ErrorMissingFrontMatter,
+		},
+		"When title is missing": {
+			input: "---\nslug: untitled\n---\nBody\n",
+			err:   ErrorMissingTitle,
+		},
+		"When date is invalid": {
+			input: "---\ntitle: Bad date\ndate: yesterday\n---\nBody\n",
+			err:   ErrorInvalidDate,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			post, err := ParseMarkdown(strings.NewReader(tc.input))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error:\ngot  %v\nwant %v\n", err, tc.err)
+			}
+			if !reflect.DeepEqual(post, tc.post) {
+				t.Errorf("unexpected post:\ngot  %+v\nwant %+v\n", post, tc.post)
+			}
+		})
+	}
+}
+
+func TestLoadMarkdownDir(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"posts/First Post.md":   "---\ntitle: First\n---\nBody",
+		"posts/bundle/index.md": "---\ntitle: Bundle\n---\nBody",
+		"posts/custom.markdown": "---\ntitle: Custom\nslug: my-slug\n---\nBody",
+		"posts/broken.md":       "no front matter",
+		"posts/image.png":       "not markdown",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, errs, err := LoadMarkdownDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slugs := map[string]bool{}
+	for _, item := range items {
+		slugs[item.Post.Slug] = true
+	}
+	want := map[string]bool{"first-post": true, "bundle": true, "my-slug": true}
+	if !reflect.DeepEqual(slugs, want) {
+		t.Errorf("unexpected slugs:\ngot  %v\nwant %v\n", slugs, want)
+	}
+	if err := errs[filepath.Join(root, "posts/broken.md")]; !errors.Is(err, ErrorMissingFrontMatter) {
+		t.Errorf("unexpected error for broken.md: %v", err)
+	}
+}
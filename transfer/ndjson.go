@@ -0,0 +1,161 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+const (
+	// FormatName identifies blogassessment exports.
+	FormatName = "blogassessment"
+
+	// FormatVersion is bumped whenever the export format changes in a way
+	// older importers cannot read. Version 2 ends NDJSON exports with a
+	// trailer.
+	FormatVersion = 2
+
+	RecordKindHeader  = "header"
+	RecordKindPost    = "post"
+	RecordKindTrailer = "trailer"
+)
+
+var (
+	ErrorUnsupportedFormat  = errors.New("unsupported export format")
+	ErrorUnsupportedVersion = errors.New("unsupported export format version")
+	ErrorTruncatedExport    = errors.New("export is truncated")
+)
+
+// Header describes an export. It is the first line of an NDJSON export and
+// the manifest of a Markdown archive.
+type Header struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// NewHeader returns the header for an export taken now.
+func NewHeader() Header {
+	return Header{Format: FormatName, Version: FormatVersion, ExportedAt: time.Now().UTC()}
+}
+
+// Validate checks that the header describes an export this build can read.
+func (h Header) Validate() error {
+	if h.Format != FormatName {
+		return fmt.Errorf("%w: %q", ErrorUnsupportedFormat, h.Format)
+	}
+	if h.Version < 1 || h.Version > FormatVersion {
+		return fmt.Errorf("%w: %d", ErrorUnsupportedVersion, h.Version)
+	}
+	return nil
+}
+
+// Trailer is the last line of an NDJSON export. An export without it, or
+// with fewer posts than it counts, was cut short.
+type Trailer struct {
+	Posts int `json:"posts"`
+}
+
+// Record is a single line of an NDJSON export. Kind tells which of the
+// payload fields is set, so new kinds can be added without breaking readers.
+type Record struct {
+	Kind    string           `json:"kind"`
+	Header  *Header          `json:"header,omitempty"`
+	Post    *models.BlogPost `json:"post,omitempty"`
+	Trailer *Trailer         `json:"trailer,omitempty"`
+}
+
+// NDJSONWriter streams an export as newline delimited JSON.
+type NDJSONWriter struct {
+	enc   *json.Encoder
+	posts int
+}
+
+// NewNDJSONWriter returns a writer that writes the export header to w first.
+func NewNDJSONWriter(w io.Writer, header Header) (*NDJSONWriter, error) {
+	writer := &NDJSONWriter{enc: json.NewEncoder(w)}
+	if err := writer.enc.Encode(Record{Kind: RecordKindHeader, Header: &header}); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WritePost writes a post record.
+func (w *NDJSONWriter) WritePost(post *models.BlogPost) error {
+	if err := w.enc.Encode(Record{Kind: RecordKindPost, Post: post}); err != nil {
+		return err
+	}
+	w.posts++
+	return nil
+}
+
+// Close writes the trailer, which marks the export as complete. It does not
+// close the underlying writer.
+func (w *NDJSONWriter) Close() error {
+	return w.enc.Encode(Record{Kind: RecordKindTrailer, Trailer: &Trailer{Posts: w.posts}})
+}
+
+// ReadNDJSON reads the posts of an NDJSON export. Unknown record kinds are
+// skipped so exports from newer minor revisions still import. Exports since
+// version 2 must end with a trailer counting their posts.
+func ReadNDJSON(r io.Reader, source string) ([]Item, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	items := []Item{}
+	line := 0
+	var header *Header
+	var trailer *Trailer
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", source, line, err)
+		}
+
+		if header == nil {
+			if record.Kind != RecordKindHeader || record.Header == nil {
+				return nil, fmt.Errorf("%s: %w: missing header", source, ErrorUnsupportedFormat)
+			}
+			if err := record.Header.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", source, err)
+			}
+			header = record.Header
+			continue
+		}
+		if trailer != nil {
+			return nil, fmt.Errorf("%s:%d: %w: record after the trailer", source, line, ErrorUnsupportedFormat)
+		}
+
+		switch {
+		case record.Kind == RecordKindPost && record.Post != nil:
+			items = append(items, Item{Source: fmt.Sprintf("%s:%d", source, line), Post: *record.Post})
+		case record.Kind == RecordKindTrailer && record.Trailer != nil:
+			trailer = record.Trailer
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("%s: %w: empty export", source, ErrorUnsupportedFormat)
+	}
+	if header.Version >= 2 {
+		if trailer == nil {
+			return nil, fmt.Errorf("%s: %w: missing trailer", source, ErrorTruncatedExport)
+		}
+		if trailer.Posts != len(items) {
+			return nil, fmt.Errorf("%s: %w: read %d of %d posts", source, ErrorTruncatedExport, len(items), trailer.Posts)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,263 @@
+package transfer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	wxrContentNamespace = "http://purl.org/rss/1.0/modules/content/"
+	wxrDateLayout       = "2006-01-02 15:04:05"
+	wxrZeroDate         = "0000-00-00 00:00:00"
+	wxrUploadsPath      = "/wp-content/uploads/"
+)
+
+// WXROptions controls how a WordPress export is mapped to blog posts.
+type WXROptions struct {
+	// AttachmentBaseURL replaces the WordPress uploads URL in post bodies,
+	// e.g. "https://cdn.example.com/media". Attachment URLs are left alone
+	// when it is empty.
+	AttachmentBaseURL string
+
+	// Rules are the length limits of the API. Titles and descriptions
+	// longer than their limit are cut to fit it.
+	Rules config.ValidationRules
+}
+
+type wxrDocument struct {
+	Channel wxrChannel `xml:"channel"`
+}
+
+type wxrChannel struct {
+	BaseSiteURL string      `xml:"base_site_url"`
+	BaseBlogURL string      `xml:"base_blog_url"`
+	Authors     []wxrAuthor `xml:"author"`
+	Items       []wxrItem   `xml:"item"`
+}
+
+type wxrAuthor struct {
+	Login       string `xml:"author_login"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrItem struct {
+	Title         string        `xml:"title"`
+	Link          string        `xml:"link"`
+	Creator       string        `xml:"creator"`
+	Encoded       []wxrEncoded  `xml:"encoded"`
+	PostID        string        `xml:"post_id"`
+	PostDate      string        `xml:"post_date"`
+	PostDateGMT   string        `xml:"post_date_gmt"`
+	ModifiedGMT   string        `xml:"post_modified_gmt"`
+	PostName      string        `xml:"post_name"`
+	Status        string        `xml:"status"`
+	PostType      string        `xml:"post_type"`
+	AttachmentURL string        `xml:"attachment_url"`
+	Categories    []wxrCategory `xml:"category"`
+	Comments      []wxrComment  `xml:"comment"`
+}
+
+// wxrEncoded holds both content:encoded and excerpt:encoded, which only
+// differ by namespace.
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID     string `xml:"comment_id"`
+	Author string `xml:"comment_author"`
+}
+
+// ReadWXR maps the posts and pages of a WordPress WXR export to blog posts.
+// Everything that cannot be imported is returned as a skipped result with
+// the reason.
+func ReadWXR(r io.Reader, source string, opts WXROptions) ([]Item, []Result, error) {
+	var doc wxrDocument
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	rewrite := attachmentRewriter(doc.Channel, opts)
+	items := []Item{}
+	skipped := []Result{}
+
+	for _, author := range doc.Channel.Authors {
+		skipped = append(skipped, Result{
+			Source: fmt.Sprintf("%s:author/%s", source, author.Login),
+			Action: ActionSkipped,
+			Err:    fmt.Errorf("authors are not supported, %q is dropped from imported posts", author.DisplayName),
+		})
+	}
+
+	for _, wi := range doc.Channel.Items {
+		itemSource := fmt.Sprintf("%s:%s/%s", source, wi.PostType, wi.PostID)
+		post, reason := wi.toBlogPost(rewrite, opts.Rules)
+		if reason != "" {
+			skipped = append(skipped, Result{Source: itemSource, Slug: wi.PostName, Action: ActionSkipped, Err: fmt.Errorf("%s", reason)})
+			continue
+		}
+
+		for _, comment := range wi.Comments {
+			skipped = append(skipped, Result{
+				Source: fmt.Sprintf("%s/comment/%s", itemSource, comment.ID),
+				Slug:   post.Slug,
+				Action: ActionSkipped,
+				Err:    fmt.Errorf("comments are not supported"),
+			})
+		}
+		items = append(items, Item{Source: itemSource, Post: *post})
+	}
+	return items, skipped, nil
+}
+
+// toBlogPost maps an item to a blog post fitting rules, or returns why it is
+// skipped. Posts without an excerpt are described by the start of their
+// content.
+func (wi *wxrItem) toBlogPost(rewrite func(string) string, rules config.ValidationRules) (*models.BlogPost, string) {
+	switch wi.PostType {
+	case "post", "page":
+	case "attachment":
+		return nil, "attachments are not imported, their URLs are rewritten in post bodies"
+	default:
+		return nil, fmt.Sprintf("unsupported post type %q", wi.PostType)
+	}
+
+	var draft bool
+	switch wi.Status {
+	case "publish":
+	case "draft", "pending", "private", "future":
+		draft = true
+	default:
+		return nil, fmt.Sprintf("unsupported status %q", wi.Status)
+	}
+
+	title := strings.TrimSpace(wi.Title)
+	if title == "" {
+		return nil, "post has no title"
+	}
+
+	var content, excerpt string
+	for _, enc := range wi.Encoded {
+		switch {
+		case enc.XMLName.Space == wxrContentNamespace:
+			content = enc.Value
+		case strings.Contains(enc.XMLName.Space, "/excerpt/"):
+			excerpt = enc.Value
+		}
+	}
+
+	slug := wi.PostName
+	if slug == "" {
+		slug = Slugify(title)
+	}
+
+	description := plainText(excerpt)
+	if description == "" {
+		description = plainText(content)
+	}
+
+	post := &models.BlogPost{
+		Title:       truncate(title, rules.Title.Max),
+		Description: truncate(description, rules.Description.Max),
+		Body:        HTMLToMarkdown(rewrite(content)),
+		Slug:        slug,
+		Tags:        wi.tags(),
+		Draft:       draft,
+	}
+
+	created := wi.PostDateGMT
+	if created == "" || created == wxrZeroDate {
+		created = wi.PostDate
+	}
+	if t, err := time.Parse(wxrDateLayout, created); err == nil {
+		post.CreatedAt = t.UTC()
+		post.UpdatedAt = post.CreatedAt
+	}
+	if t, err := time.Parse(wxrDateLayout, wi.ModifiedGMT); err == nil && wi.ModifiedGMT != wxrZeroDate {
+		post.UpdatedAt = t.UTC()
+	}
+	return post, ""
+}
+
+// tags merges the categories and tags of an item, without duplicates.
+func (wi *wxrItem) tags() []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, c := range wi.Categories {
+		if c.Domain != "category" && c.Domain != "post_tag" {
+			continue
+		}
+		name := strings.TrimSpace(c.Name)
+		if name == "" || name == "Uncategorized" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// attachmentRewriter returns a func replacing the site's uploads URL with
+// opts.AttachmentBaseURL, for both http and https.
+func attachmentRewriter(channel wxrChannel, opts WXROptions) func(string) string {
+	base := strings.TrimRight(opts.AttachmentBaseURL, "/")
+	if base == "" {
+		return func(s string) string { return s }
+	}
+
+	var pairs []string
+	for _, site := range []string{channel.BaseSiteURL, channel.BaseBlogURL} {
+		site = strings.TrimRight(site, "/")
+		if site == "" {
+			continue
+		}
+		host := strings.TrimPrefix(strings.TrimPrefix(site, "https://"), "http://")
+		for _, scheme := range []string{"https://", "http://"} {
+			pairs = append(pairs, scheme+host+wxrUploadsPath, base+"/")
+		}
+	}
+	replacer := strings.NewReplacer(pairs...)
+	return replacer.Replace
+}
+
+// plainText strips the markup from an HTML fragment.
+func plainText(src string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.Join(strings.Fields(src), " ")
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(textContent(n))
+		b.WriteString(" ")
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// truncate cuts s to max characters, ending it with an ellipsis. A max of
+// zero leaves s as it is.
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
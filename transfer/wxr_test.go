@@ -0,0 +1,154 @@
+package transfer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+const sampleWXR = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<wp:base_site_url>http://old.example.com</wp:base_site_url>
+	<wp:author><wp:author_login>admin</wp:author_login><wp:author_display_name>Admin</wp:author_display_name></wp:author>
+	<item>
+		<title>Hello World</title>
+		<dc:creator>admin</dc:creator>
+		<content:encoded><![CDATA[Welcome to <strong>WordPress</strong>.
+
+<img src="https://old.example.com/wp-content/uploads/2020/01/cat.jpg" alt="Cat">]]></content:encoded>
+		<excerpt:encoded><![CDATA[<p>A <em>short</em> excerpt</p>]]></excerpt:encoded>
+		<wp:post_id>1</wp:post_id>
+		<wp:post_date>2020-01-02 12:00:00</wp:post_date>
+		<wp:post_date_gmt>2020-01-02 10:00:00</wp:post_date_gmt>
+		<wp:post_modified_gmt>2020-02-03 10:00:00</wp:post_modified_gmt>
+		<wp:post_name>hello-world</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+		<category domain="category" nicename="news"><![CDATA[News]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+		<wp:comment><wp:comment_id>7</wp:comment_id><wp:comment_author>Bob</wp:comment_author></wp:comment>
+	</item>
+	<item>
+		<title>About</title>
+		<content:encoded><![CDATA[<p>About page</p>]]></content:encoded>
+		<wp:post_id>2</wp:post_id>
+		<wp:post_date>2020-01-03 12:00:00</wp:post_date>
+		<wp:post_date_gmt>0000-00-00 00:00:00</wp:post_date_gmt>
+		<wp:post_name></wp:post_name>
+		<wp:status>draft</wp:status>
+		<wp:post_type>page</wp:post_type>
+	</item>
+	<item>
+		<title>cat.jpg</title>
+		<wp:post_id>3</wp:post_id>
+		<wp:status>inherit</wp:status>
+		<wp:post_type>attachment</wp:post_type>
+		<wp:attachment_url>http://old.example.com/wp-content/uploads/2020/01/cat.jpg</wp:attachment_url>
+	</item>
+	<item>
+		<title>Menu</title>
+		<wp:post_id>4</wp:post_id>
+		<wp:status>publish</wp:status>
+		<wp:post_type>nav_menu_item</wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+func TestReadWXR(t *testing.T) {
+	items, skipped, err := ReadWXR(strings.NewReader(sampleWXR), "wp.xml", WXROptions{AttachmentBaseURL: "https://cdn.example.com/media/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.BlogPost{
+		{
+			Title:       "Hello World",
+			Description: "A short excerpt",
+			Body:        "Welcome to **WordPress**.\n\n![Cat](https://cdn.example.com/media/2020/01/cat.jpg)",
+			Slug:        "hello-world",
+			Tags:        []string{"News", "Go"},
+			CreatedAt:   mustTime("2020-01-02T10:00:00Z"),
+			UpdatedAt:   mustTime("2020-02-03T10:00:00Z"),
+		},
+		{
+			Title:       "About",
+			Description: "About page",
+			Body:        "About page",
+			Slug:        "about",
+			Draft:       true,
+			CreatedAt:   mustTime("2020-01-03T12:00:00Z"),
+			UpdatedAt:   mustTime("2020-01-03T12:00:00Z"),
+		},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("unexpected number of items: got %d want %d", len(items), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(items[i].Post, want[i]) {
+			t.Errorf("unexpected post %d:\ngot  %+v\nwant %+v\n", i, items[i].Post, want[i])
+		}
+	}
+
+	sources := []string{}
+	for _, s := range skipped {
+		if s.Action != ActionSkipped || s.Err == nil {
+			t.Errorf("skipped item %s has no reason", s.Source)
+		}
+		sources = append(sources, s.Source)
+	}
+	wantSources := []string{"wp.xml:author/admin", "wp.xml:post/1/comment/7", "wp.xml:attachment/3", "wp.xml:nav_menu_item/4"}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("unexpected skipped items:\ngot  %v\nwant %v\n", sources, wantSources)
+	}
+
+	rules := config.DefaultValidationRules()
+	rules.Title.Max, rules.Description.Max = 8, 6
+	items, _, err = ReadWXR(strings.NewReader(sampleWXR), "wp.xml", WXROptions{Rules: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post := items[0].Post; post.Title != "Hello W…" || post.Description != "A sho…" || post.Slug != "hello-world" {
+		t.Errorf("post was not cut to the rules: %+v", post)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	cases := map[string]struct {
+		html string
+		md   string
+	}{
+		"When content has no paragraph tags": {
+			html: "First line\nsecond line\n\nNext <em>paragraph</em>",
+			md:   "First line  \nsecond line\n\nNext _paragraph_",
+		},
+		"When content has headings, links and lists": {
+			html: `<h2>Title</h2><p>See <a href="https://example.com">this</a></p><ul><li>one</li><li>two<ol><li>nested</li></ol></li></ul>`,
+			md:   "## Title\n\nSee [this](https://example.com)\n\n- one\n- two\n  1. nested",
+		},
+		"When content has quotes and code": {
+			html: "<blockquote><p>quoted</p></blockquote><pre><code>a := 1\nb := 2</code></pre><p>inline <code>x</code></p>",
+			md:   "> quoted\n\n```\na := 1\nb := 2\n```\n\ninline `x`",
+		},
+		"When content has no Markdown equivalent": {
+			html: "<p>before</p><table><tr><td>cell</td></tr></table>",
+			md:   "before\n\n<table><tbody><tr><td>cell</td></tr></tbody></table>",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := HTMLToMarkdown(tc.html); got != tc.md {
+				t.Errorf("unexpected markdown:\ngot  %q\nwant %q\n", got, tc.md)
+			}
+		})
+	}
+}
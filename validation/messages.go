@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+)
+
+// keyFallback is the message of a validator tag missing from a catalog.
+const keyFallback = "fallback"
+
+// keyMinItems and keyMaxItems are the messages of the min and max tags on
+// lists, whose lengths count items rather than characters.
+const (
+	keyMinItems = "min_items"
+	keyMaxItems = "max_items"
+)
+
+// Catalog maps a validator tag, or one of the Code* constants, to a message
+// template. {0} and {1} are replaced by the parameters of the message, e.g.
+// the param of the tag ("5" for min=5).
+type Catalog map[string]string
+
+// English is the default catalog, used when no registered locale matches
+// the Accept-Language of a request.
+var English = Catalog{
+	"required":        "is required",
+	"min":             "should at least have {0} characters",
+	"max":             "should not exceed {0} characters",
+	keyMinItems:       "should hold at least {0} item(s)",
+	keyMaxItems:       "should hold at most {0} item(s)",
+	"uuid":            "must be a valid UUID",
+	"timezone":        "must be a valid IANA time zone",
+	"oneof":           "must be one of {0}",
+	"required_unless": "is required",
+	"excluded_if":     "must not be given",
+	"slug":            "must be lowercase letters and digits separated by single hyphens",
+	CodeInvalidType:   "{0} cannot be a {1}",
+	CodeEmptyBody:     "request body cannot be empty",
+	CodeInvalid:       "the request could not be read",
+	CodeUnknownField:  "is not a known field",
+	CodeDuplicateKey:  "is given more than once",
+	CodeTrailingData:  "request body must hold a single JSON value",
+	CodeMalformedJSON: "is not valid JSON",
+	keyFallback:       "is invalid",
+}
+
+// German is a reference catalog for adding further locales.
+var German = Catalog{
+	"required":        "ist erforderlich",
+	"min":             "muss mindestens {0} Zeichen lang sein",
+	"max":             "darf höchstens {0} Zeichen lang sein",
+	keyMinItems:       "muss mindestens {0} Einträge enthalten",
+	keyMaxItems:       "darf höchstens {0} Einträge enthalten",
+	"uuid":            "muss eine gültige UUID sein",
+	"timezone":        "muss eine gültige IANA-Zeitzone sein",
+	"oneof":           "muss einer der Werte {0} sein",
+	"required_unless": "ist erforderlich",
+	"excluded_if":     "darf nicht angegeben werden",
+	"slug":            "darf nur aus Kleinbuchstaben und Ziffern bestehen, getrennt durch einzelne Bindestriche",
+	CodeInvalidType:   "{0} darf kein {1} sein",
+	CodeEmptyBody:     "der Anfragetext darf nicht leer sein",
+	CodeInvalid:       "die Anfrage konnte nicht gelesen werden",
+	CodeUnknownField:  "ist kein bekanntes Feld",
+	CodeDuplicateKey:  "ist mehrfach angegeben",
+	CodeTrailingData:  "der Anfragetext darf nur einen JSON-Wert enthalten",
+	CodeMalformedJSON: "ist kein gültiges JSON",
+	keyFallback:       "ist ungültig",
+}
+
+var translators = ut.New(en.New())
+
+func init() {
+	if err := RegisterLocale(en.New(), English); err != nil {
+		panic(err)
+	}
+	if err := RegisterLocale(de.New(), German); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterLocale adds the messages of a locale, replacing any registered
+// before. It is not safe for concurrent use and belongs in program startup.
+func RegisterLocale(locale locales.Translator, catalog Catalog) error {
+	if err := translators.AddTranslator(locale, true); err != nil {
+		return err
+	}
+	trans, _ := translators.GetTranslator(locale.Locale())
+	for key, text := range catalog {
+		if err := trans.Add(key, text, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// translator returns the translator of the first of languages that has a
+// registered locale, trying "de-AT" as de_AT before de.
+func translator(languages []string) ut.Translator {
+	candidates := make([]string, 0, 2*len(languages))
+	for _, language := range languages {
+		tag := strings.ReplaceAll(language, "-", "_")
+		candidates = append(candidates, tag)
+		if base, _, ok := strings.Cut(tag, "_"); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	trans, _ := translators.FindTranslator(candidates...)
+	return trans
+}
+
+// message renders key in trans, falling back to the generic message of the
+// locale and then to English when trans has no such key.
+func message(trans ut.Translator, key string, params ...string) string {
+	if text, err := trans.T(key, params...); err == nil {
+		return text
+	}
+	if text, err := trans.T(keyFallback); err == nil {
+		return text
+	}
+	return English[keyFallback]
+}
+
+// AcceptLanguage returns the language tags of an Accept-Language header,
+// most preferred first. Tags with q=0 and the wildcard are dropped.
+func AcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	languages := make([]string, len(tags))
+	for i, t := range tags {
+		languages[i] = t.tag
+	}
+	return languages
+}
@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestAcceptLanguage(t *testing.T) {
+	cases := map[string]struct {
+		header string
+		want   []string
+	}{
+		"empty":             {"", []string{}},
+		"single":            {"de", []string{"de"}},
+		"ordered by weight": {"en;q=0.5, de-AT, fr;q=0.8", []string{"de-AT", "fr", "en"}},
+		"drops q=0 and *":   {"de;q=0, *, en", []string{"en"}},
+		"drops bad weights": {"de;q=x, en", []string{"en"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := AcceptLanguage(tc.header); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("AcceptLanguage(%q) = %q, want %q", tc.header, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCustomValidationErrorFallsBackToGenericMessage(t *testing.T) {
+	request := struct {
+		Email string `validate:"email"`
+		Title string `validate:"min=5"`
+	}{Email: "not-an-email", Title: "abc"}
+	err := validator.New().Struct(request)
+
+	if err := RegisterLocale(fr.New(), Catalog{keyFallback: "est invalide"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		languages []string
+		want      []FieldError
+	}{
+		"English": {nil, []FieldError{
+			{Field: "Email", Code: "email", Message: "is invalid"},
+			{Field: "Title", Code: "min", Message: "should at least have 5 characters"},
+		}},
+		"German": {[]string{"de-DE"}, []FieldError{
+			{Field: "Email", Code: "email", Message: "ist ungültig"},
+			{Field: "Title", Code: "min", Message: "muss mindestens 5 Zeichen lang sein"},
+		}},
+		"partial catalog": {[]string{"fr"}, []FieldError{
+			{Field: "Email", Code: "email", Message: "est invalide"},
+			{Field: "Title", Code: "min", Message: "est invalide"},
+		}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := CustomValidationError(err, tc.languages...); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("CustomValidationError() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
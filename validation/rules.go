@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// slugPattern matches the slugs transfer.Slugify produces: lowercase letters
+// and digits, in runs joined by single hyphens.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// NewValidator returns a validator that checks the binding tags of a request
+// and the configured length rules of its blog post fields. Rule violations
+// are reported with the min and max tags, so their messages carry the
+// configured values. Fields are named as the client sent them, after their
+// json, uri or form tag.
+func NewValidator(rules config.ValidationRules) *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(fieldName)
+	// An empty slug is no slug. Registering cannot fail: the tag is new
+	// and the function set.
+	_ = v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		slug := fl.Field().String()
+		return slug == "" || slugPattern.MatchString(slug)
+	})
+
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		// Empty fields are left to the required tag.
+		req := sl.Current().Interface().(models.CreateBlogPostRequest)
+		for _, f := range []struct {
+			name  string
+			value string
+			rule  config.LengthRule
+		}{
+			{"Title", req.Title, rules.Title},
+			{"Description", req.Description, rules.Description},
+			{"Body", req.Body, rules.Body},
+		} {
+			if f.value != "" {
+				checkLength(sl, f.name, f.value, f.rule)
+			}
+		}
+	}, models.CreateBlogPostRequest{})
+
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		// Absent fields are left unchanged by an update.
+		req := sl.Current().Interface().(models.UpdateBlogPostRequest)
+		for _, f := range []struct {
+			name  string
+			value *string
+			rule  config.LengthRule
+		}{
+			{"Title", req.Title, rules.Title},
+			{"Description", req.Description, rules.Description},
+			{"Body", req.Body, rules.Body},
+		} {
+			if f.value != nil {
+				checkLength(sl, f.name, *f.value, f.rule)
+			}
+		}
+	}, models.UpdateBlogPostRequest{})
+
+	return v
+}
+
+// checkLength reports the struct field called field, under the name of its
+// tag, when its length in characters breaks rule.
+func checkLength(sl validator.StructLevel, field, value string, rule config.LengthRule) {
+	name := field
+	if f, ok := sl.Current().Type().FieldByName(field); ok {
+		name = fieldName(f)
+	}
+	switch n := utf8.RuneCountInString(value); {
+	case n < rule.Min:
+		sl.ReportError(value, name, field, "min", strconv.Itoa(rule.Min))
+	case rule.Max > 0 && n > rule.Max:
+		sl.ReportError(value, name, field, "max", strconv.Itoa(rule.Max))
+	}
+}
+
+// fieldName returns the name of f in its json, uri or form tag, or "" to
+// keep the Go name.
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "uri", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DurgeshKr2242/blogassessment/config"
+	"github.com/DurgeshKr2242/blogassessment/models"
+)
+
+func TestNewValidatorNamesFieldsAfterTags(t *testing.T) {
+	v := NewValidator(config.DefaultValidationRules())
+
+	cases := map[string]struct {
+		value any
+		want  []string
+	}{
+		"json": {models.CreateBlogPostRequest{Title: "abc"}, []string{"description", "body", "title"}},
+		"uri": {struct {
+			ID string `uri:"ID" binding:"required,uuid"`
+		}{ID: "x"}, []string{"ID"}},
+		"form": {struct {
+			TZ string `form:"tz" binding:"omitempty,timezone"`
+		}{TZ: "Mars/Olympus"}, []string{"tz"}},
+		"untagged": {struct {
+			Name string `binding:"required"`
+		}{}, []string{"Name"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, e := range CustomValidationError(v.Struct(tc.value)) {
+				got = append(got, e.Field)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("fields %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewValidatorChecksSlugs(t *testing.T) {
+	v := NewValidator(config.DefaultValidationRules())
+
+	cases := map[string]bool{
+		"":              true,
+		"go":            true,
+		"hello-world-2": true,
+		"Hello":         false,
+		"two--hyphens":  false,
+		"-leading":      false,
+		"trailing-":     false,
+		"with space":    false,
+		"ümlaut":        false,
+	}
+	for slug, valid := range cases {
+		t.Run(slug, func(t *testing.T) {
+			err := v.Struct(models.EditableBlogPost{Slug: slug})
+			if (err == nil) != valid {
+				t.Errorf("slug %q: got error %v, want valid %v", slug, err, valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// JSONError reports a request body that is not strict JSON. Path locates the
+// offending member, e.g. "title" or "tags[2]", and is empty for the body as
+// a whole.
+type JSONError struct {
+	Path string
+	Code string
+}
+
+func (e *JSONError) Error() string {
+	if e.Path == "" {
+		return "json: " + e.Code
+	}
+	return fmt.Sprintf("json: %s at %s", e.Code, e.Path)
+}
+
+// DecodeStrict unmarshals data into v like json.Unmarshal, but rejects
+// members v has no field for, keys given twice in one object and anything
+// after the first JSON value. An empty body returns io.EOF.
+func DecodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := checkValue(dec, reflect.TypeOf(v), ""); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return &JSONError{Code: CodeTrailingData}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkValue reads the next value from dec, checking objects decoded into
+// a struct of type t for unknown and duplicate members. t is nil when the
+// value is decoded into an interface.
+func checkValue(dec *json.Decoder, t reflect.Type, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if path == "" && errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+		return &JSONError{Path: path, Code: CodeMalformedJSON}
+	}
+
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return &JSONError{Path: path, Code: CodeMalformedJSON}
+			}
+			key := keyTok.(string)
+			memberPath := joinPath(path, key)
+
+			var memberType reflect.Type
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Struct:
+				field, ok := lookupField(t, key)
+				if !ok {
+					return &JSONError{Path: memberPath, Code: CodeUnknownField}
+				}
+				// encoding/json matches keys case-insensitively, so "Title"
+				// and "title" set the same field.
+				key = field.Name
+				memberType = field.Type
+			case t.Kind() == reflect.Map:
+				memberType = t.Elem()
+			}
+
+			if seen[key] {
+				return &JSONError{Path: memberPath, Code: CodeDuplicateKey}
+			}
+			seen[key] = true
+
+			if err := checkValue(dec, memberType, memberPath); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return &JSONError{Path: path, Code: CodeMalformedJSON}
+		}
+
+	case json.Delim('['):
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for i := 0; dec.More(); i++ {
+			if err := checkValue(dec, elemType, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return &JSONError{Path: path, Code: CodeMalformedJSON}
+		}
+	}
+	return nil
+}
+
+// lookupField finds the field of struct t that encoding/json decodes key
+// into, preferring an exact match of its name over a case-insensitive one.
+func lookupField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field, true
+		}
+		if folded == nil && strings.EqualFold(name, key) {
+			folded = &field
+		}
+	}
+	if folded != nil {
+		return *folded, true
+	}
+	return reflect.StructField{}, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
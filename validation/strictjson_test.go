@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type strictPost struct {
+	Title  string            `json:"title"`
+	Tags   []string          `json:"tags"`
+	Author *strictAuthor     `json:"author"`
+	Extra  map[string]any    `json:"extra"`
+	Meta   map[string]string `json:"-"`
+}
+
+type strictAuthor struct {
+	Name string `json:"name"`
+}
+
+func TestDecodeStrict(t *testing.T) {
+	cases := map[string]struct {
+		body string
+		want error
+	}{
+		"valid":                 {`{"title": "t", "tags": ["a"], "author": {"name": "n"}, "extra": {"k": {"x": 1}}}`, nil},
+		"case-insensitive key":  {`{"Title": "t"}`, nil},
+		"empty":                 {"  ", io.EOF},
+		"unknown field":         {`{"titel": "t"}`, &JSONError{Path: "titel", Code: CodeUnknownField}},
+		"ignored field":         {`{"Meta": {}}`, &JSONError{Path: "Meta", Code: CodeUnknownField}},
+		"nested unknown field":  {`{"author": {"nmae": "n"}}`, &JSONError{Path: "author.nmae", Code: CodeUnknownField}},
+		"duplicate key":         {`{"title": "a", "title": "b"}`, &JSONError{Path: "title", Code: CodeDuplicateKey}},
+		"case-folded duplicate": {`{"title": "a", "TITLE": "b"}`, &JSONError{Path: "TITLE", Code: CodeDuplicateKey}},
+		"duplicate in map":      {`{"extra": {"k": 1, "k": 2}}`, &JSONError{Path: "extra.k", Code: CodeDuplicateKey}},
+		"duplicate in array":    {`{"extra": {"l": [{"a": 1}, {"a": 1, "a": 2}]}}`, &JSONError{Path: "extra.l[1].a", Code: CodeDuplicateKey}},
+		"trailing data":         {`{"title": "t"} x`, &JSONError{Code: CodeTrailingData}},
+		"second value":          {`{"title": "t"}{}`, &JSONError{Code: CodeTrailingData}},
+		"malformed member":      {`{"tags": ["a",]}`, &JSONError{Path: "tags[1]", Code: CodeMalformedJSON}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var post strictPost
+			err := DecodeStrict([]byte(tc.body), &post)
+			if tc.want == io.EOF {
+				if !errors.Is(err, io.EOF) {
+					t.Errorf("DecodeStrict() = %v, want io.EOF", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(err, tc.want) {
+				t.Errorf("DecodeStrict() = %#v, want %#v", err, tc.want)
+			}
+		})
+	}
+}
@@ -3,67 +3,104 @@ package validation
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
+	"reflect"
 
 	"github.com/go-playground/validator/v10"
 )
 
-var (
-	errIsRequired = errors.New("is required")
-	errMin5       = errors.New("should at least have 5 characters")
-	errMax60      = errors.New("should not exceed 60 characters")
-	errMin10      = errors.New("should at least have 10 characters")
-	errMax300     = errors.New("should not exceed 300 characters")
-	errUUID       = errors.New("must be a valid UUID")
+// Codes of field errors that do not come from a validator tag.
+const (
+	CodeInvalidType = "invalid_type"
+	CodeEmptyBody   = "empty_body"
+	CodeInvalid     = "invalid"
 
-	customErrors = map[string]error{
-		"ID.required":          errIsRequired,
-		"ID.uuid":              errUUID,
-		"Title.required":       errIsRequired,
-		"Title.min":            errMin5,
-		"Title.max":            errMax60,
-		"Description.required": errIsRequired,
-		"Description.min":      errMin10,
-		"Description.max":      errMax300,
-		"Body.required":        errIsRequired,
-		"Body.min":             errMin10,
-	}
+	CodeUnknownField  = "unknown_field"
+	CodeDuplicateKey  = "duplicate_key"
+	CodeTrailingData  = "trailing_data"
+	CodeMalformedJSON = "malformed_json"
 )
 
-// CustomValidationError converts validator errors to a slice of custom error messages.
-func CustomValidationError(err error) []map[string]string {
-	errs := make([]map[string]string, 0)
+// FieldError describes why one field of a request was rejected. Code is the
+// validator tag that failed (e.g. "required", "min", "uuid") or one of the
+// Code* constants, and is stable across releases unlike Message. Index is
+// the position of the item of a batch request the field belongs to, and nil
+// outside batches.
+type FieldError struct {
+	Index   *int   `json:"index,omitempty"`
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AtIndex sets the batch item index of errs and returns them.
+func AtIndex(errs []FieldError, index int) []FieldError {
+	for i := range errs {
+		errs[i].Index = &index
+	}
+	return errs
+}
+
+// messageKey returns the catalog key of the message of e, its tag unless
+// the tag counts items of a list.
+func messageKey(e validator.FieldError) string {
+	switch e.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		switch e.Tag() {
+		case "min":
+			return keyMinItems
+		case "max":
+			return keyMaxItems
+		}
+	}
+	return e.Tag()
+}
+
+// CustomValidationError converts validator errors to a slice of field errors,
+// with messages in the first of languages that has a registered locale, or
+// in English.
+func CustomValidationError(err error, languages ...string) []FieldError {
+	trans := translator(languages)
+	errs := make([]FieldError, 0)
 	switch errTypes := err.(type) {
 
 	case validator.ValidationErrors:
 		for _, e := range errTypes {
-			errorMap := make(map[string]string)
-			// Create the key as Field.Tag (e.g., "Title.required", "ID.uuid")
-			key := e.Field() + "." + e.Tag()
-			if v, ok := customErrors[key]; ok {
-				errorMap[e.Field()] = v.Error()
-			} else {
-				errorMap[e.Field()] = fmt.Sprintf("custom message is not available: %v", err)
-			}
-			errs = append(errs, errorMap)
+			errs = append(errs, FieldError{
+				Field:   e.Field(),
+				Code:    e.Tag(),
+				Message: message(trans, messageKey(e), e.Param()),
+			})
 		}
 		return errs
 
+	case *JSONError:
+		errs = append(errs, FieldError{
+			Field:   errTypes.Path,
+			Code:    errTypes.Code,
+			Message: message(trans, errTypes.Code),
+		})
+		return errs
+
 	case *json.UnmarshalTypeError:
-		errs = append(errs, map[string]string{
-			errTypes.Field: fmt.Sprintf("%v cannot be a %v", errTypes.Field, errTypes.Value),
+		errs = append(errs, FieldError{
+			Field:   errTypes.Field,
+			Code:    CodeInvalidType,
+			Message: message(trans, CodeInvalidType, errTypes.Field, errTypes.Value),
 		})
 		return errs
 	}
 
 	if errors.Is(err, io.EOF) {
-		errs = append(errs, map[string]string{
-			"body": "request body cannot be empty",
+		errs = append(errs, FieldError{
+			Field:   "body",
+			Code:    CodeEmptyBody,
+			Message: message(trans, CodeEmptyBody),
 		})
 	} else {
-		errs = append(errs, map[string]string{
-			"unknown": fmt.Sprintf("unsupported custom error for: %v", err),
+		errs = append(errs, FieldError{
+			Code:    CodeInvalid,
+			Message: message(trans, CodeInvalid),
 		})
 	}
 	return errs